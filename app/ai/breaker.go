@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// ErrCircuitOpen is returned without calling the AI service while the circuit is open.
+var ErrCircuitOpen = errors.New("AI service circuit is open")
+
+// CircuitState is the state of a CircuitBreakerService.
+type CircuitState string
+
+const (
+	// CircuitClosed passes every call to the AI service.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects every call until the cooldown has passed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single call probe whether the AI service has recovered.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+var _ domain.AIService = (*CircuitBreakerService)(nil)
+
+// CircuitBreakerService stops calling the AI service after consecutive failures, so a provider outage
+// fails fast instead of holding every check-in until it times out.
+type CircuitBreakerService struct {
+	next      domain.AIService
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	probing  bool
+}
+
+// NewCircuitBreakerService creates a new CircuitBreakerService which opens after threshold consecutive
+// failures, and probes the AI service again after the cooldown.
+func NewCircuitBreakerService(next domain.AIService, threshold int, cooldown time.Duration) *CircuitBreakerService {
+	return &CircuitBreakerService{next: next, threshold: max(threshold, 1), cooldown: cooldown, now: time.Now}
+}
+
+// State returns the current state of the circuit.
+func (s *CircuitBreakerService) State() CircuitState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state()
+}
+
+func (s *CircuitBreakerService) state() CircuitState {
+	switch {
+	case !s.open:
+		return CircuitClosed
+	case s.now().Sub(s.openedAt) >= s.cooldown:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
+
+// acquire reports whether a call may be made, only one probe is allowed while the circuit is half-open.
+func (s *CircuitBreakerService) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state() {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if s.probing {
+			return ErrCircuitOpen
+		}
+		s.probing = true
+	}
+
+	return nil
+}
+
+// release records the result of a call made after acquire.
+func (s *CircuitBreakerService) release(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.probing = false
+
+	// The caller giving up says nothing about the health of the AI service.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err == nil {
+		s.failures = 0
+		s.open = false
+		return
+	}
+
+	s.failures++
+	if s.open || s.failures >= s.threshold {
+		s.open = true
+		s.openedAt = s.now()
+	}
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *CircuitBreakerService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	if err := s.acquire(); err != nil {
+		return 0, err
+	}
+
+	score, err := s.next.GetEmotionScore(ctx, input)
+	s.release(err)
+	return score, err
+}
+
+// GenerateTaskSuggestion implements domain.AIService.
+func (s *CircuitBreakerService) GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error) {
+	if err := s.acquire(); err != nil {
+		return "", err
+	}
+
+	task, err := s.next.GenerateTaskSuggestion(ctx, emoji, description, score)
+	s.release(err)
+	return task, err
+}
+
+// GenerateDailySummary implements domain.AIService.
+func (s *CircuitBreakerService) GenerateDailySummary(ctx context.Context, averageScore float64) (string, error) {
+	if err := s.acquire(); err != nil {
+		return "", err
+	}
+
+	summary, err := s.next.GenerateDailySummary(ctx, averageScore)
+	s.release(err)
+	return summary, err
+}
+
+// DetectCrisis implements domain.AIService. The safety check is never cut off by the circuit, and
+// neither counts towards it, as skipping it on a few failures would leave only the keyword list.
+func (s *CircuitBreakerService) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	return s.next.DetectCrisis(ctx, input)
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// fakeService returns err from every call and counts the calls.
+type fakeService struct {
+	domain.AIService
+	err   error
+	calls int
+}
+
+func (f *fakeService) GetEmotionScore(context.Context, string) (int, error) {
+	f.calls++
+	return 50, f.err
+}
+
+func (f *fakeService) DetectCrisis(context.Context, string) (bool, error) {
+	f.calls++
+	return true, f.err
+}
+
+func TestCircuitBreakerService(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	next := &fakeService{err: errors.New("unavailable")}
+	breaker := NewCircuitBreakerService(next, 2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	for range 2 {
+		_, err := breaker.GetEmotionScore(ctx, "")
+		s.ErrorContains(err, "unavailable")
+	}
+	s.Equal(CircuitOpen, breaker.State())
+
+	_, err := breaker.GetEmotionScore(ctx, "")
+	s.ErrorIs(err, ErrCircuitOpen)
+	s.Equal(2, next.calls)
+
+	// The probe after the cooldown fails, so the circuit opens again.
+	now = now.Add(time.Minute)
+	s.Equal(CircuitHalfOpen, breaker.State())
+	_, err = breaker.GetEmotionScore(ctx, "")
+	s.ErrorContains(err, "unavailable")
+	s.Equal(CircuitOpen, breaker.State())
+
+	// The probe succeeds and closes the circuit.
+	now = now.Add(time.Minute)
+	next.err = nil
+	score, err := breaker.GetEmotionScore(ctx, "")
+	s.NoError(err)
+	s.Equal(50, score)
+	s.Equal(CircuitClosed, breaker.State())
+	s.Equal(4, next.calls)
+}
+
+func TestCircuitBreakerServiceDetectCrisis(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	next := &fakeService{err: errors.New("unavailable")}
+	breaker := NewCircuitBreakerService(next, 1, time.Minute)
+
+	_, err := breaker.GetEmotionScore(ctx, "")
+	s.ErrorContains(err, "unavailable")
+	s.Equal(CircuitOpen, breaker.State())
+
+	// The safety check still reaches the AI service while the circuit is open.
+	next.err = nil
+	crisis, err := breaker.DetectCrisis(ctx, "")
+	s.NoError(err)
+	s.True(crisis)
+	s.Equal(2, next.calls)
+	s.Equal(CircuitOpen, breaker.State())
+}
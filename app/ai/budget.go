@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.AIService = (*BudgetService)(nil)
+
+// BudgetService enforces a daily token budget per user, the day starts at midnight UTC. Calls without
+// an actor in the context are not limited. DetectCrisis is never limited, so the safety escalation keeps
+// working for users over the budget.
+type BudgetService struct {
+	next        domain.AIService
+	repo        domain.AIUsageRepository
+	dailyTokens int
+	now         func() time.Time
+}
+
+// NewBudgetService creates a new BudgetService which allows dailyTokens per user.
+func NewBudgetService(next domain.AIService, repo domain.AIUsageRepository, dailyTokens int) *BudgetService {
+	return &BudgetService{next: next, repo: repo, dailyTokens: dailyTokens, now: time.Now}
+}
+
+// check returns domain.ErrAIBudgetExceeded when the user of the context used up the budget.
+func (s *BudgetService) check(ctx context.Context) error {
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || actor.UserID == "" {
+		return nil
+	}
+
+	since := s.now().UTC().Truncate(24 * time.Hour)
+	summaries, err := s.repo.SumAIUsage(ctx, domain.SumAIUsageRequest{UserID: actor.UserID, Since: &since})
+	if err != nil {
+		return fmt.Errorf("checking AI token budget failed: %w", err)
+	}
+
+	var used int
+	for _, summary := range summaries {
+		used += summary.PromptTokens + summary.CompletionTokens
+	}
+	if used >= s.dailyTokens {
+		return domain.ErrAIBudgetExceeded
+	}
+
+	return nil
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *BudgetService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	if err := s.check(ctx); err != nil {
+		return 0, err
+	}
+	return s.next.GetEmotionScore(ctx, input)
+}
+
+// GenerateTaskSuggestion implements domain.AIService.
+func (s *BudgetService) GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error) {
+	if err := s.check(ctx); err != nil {
+		return "", err
+	}
+	return s.next.GenerateTaskSuggestion(ctx, emoji, description, score)
+}
+
+// GenerateDailySummary implements domain.AIService.
+func (s *BudgetService) GenerateDailySummary(ctx context.Context, averageScore float64) (string, error) {
+	if err := s.check(ctx); err != nil {
+		return "", err
+	}
+	return s.next.GenerateDailySummary(ctx, averageScore)
+}
+
+// DetectCrisis implements domain.AIService. It is never limited by the budget.
+func (s *BudgetService) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	return s.next.DetectCrisis(ctx, input)
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// fakeUsageRepository returns the summaries regardless of the request.
+type fakeUsageRepository struct {
+	domain.AIUsageRepository
+	summaries []domain.AIUsageSummary
+}
+
+func (f *fakeUsageRepository) SumAIUsage(context.Context, domain.SumAIUsageRequest) ([]domain.AIUsageSummary, error) {
+	return f.summaries, nil
+}
+
+func TestBudgetService(t *testing.T) {
+	s := require.New(t)
+
+	repo := &fakeUsageRepository{summaries: []domain.AIUsageSummary{{PromptTokens: 60, CompletionTokens: 30}}}
+	next := &fakeService{}
+	budget := NewBudgetService(next, repo, 100)
+
+	ctx := domain.WithActor(context.Background(), domain.Actor{TeamID: "T1", UserID: "U1"})
+	_, err := budget.GetEmotionScore(ctx, "")
+	s.NoError(err)
+
+	repo.summaries = append(repo.summaries, domain.AIUsageSummary{CompletionTokens: 10})
+	_, err = budget.GetEmotionScore(ctx, "")
+	s.ErrorIs(err, domain.ErrAIBudgetExceeded)
+	s.Equal(1, next.calls)
+
+	// Calls without a user are not limited.
+	_, err = budget.GetEmotionScore(context.Background(), "")
+	s.NoError(err)
+	s.Equal(2, next.calls)
+}
+
+func TestParsePricing(t *testing.T) {
+	s := require.New(t)
+
+	pricing, err := ParsePricing([]string{"gemini-1.5-flash=0.075:0.3"})
+	s.NoError(err)
+
+	cost, ok := pricing.Cost("gemini-1.5-flash", 1_000_000, 2_000_000)
+	s.True(ok)
+	s.InDelta(0.675, cost, 1e-9)
+
+	_, ok = pricing.Cost("gemini-1.5-pro", 1, 1)
+	s.False(ok)
+
+	for _, value := range []string{"gemini", "=1:2", "gemini=1", "gemini=a:1", "gemini=1:-1"} {
+		_, err := ParsePricing([]string{value})
+		s.Error(err, value)
+	}
+}
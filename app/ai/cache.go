@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/pkg/cache"
+)
+
+var _ domain.AIService = (*CachingService)(nil)
+
+// CachingService caches the emotion scores, so repeated inputs like a single emoji are scored instantly
+// and consistently. The other methods are not cached, as their replies are meant to vary.
+type CachingService struct {
+	domain.AIService
+	store     cache.Store
+	namespace string
+	ttl       time.Duration
+}
+
+// NewCachingService creates a new CachingService. The namespace, e.g. the model and prompt version,
+// is part of the key, so changing it invalidates the cached scores.
+func NewCachingService(next domain.AIService, store cache.Store, namespace string, ttl time.Duration) *CachingService {
+	return &CachingService{AIService: next, store: store, namespace: namespace, ttl: ttl}
+}
+
+// key returns the cache key of the input. The input is hashed so the cache never stores what users wrote.
+func (s *CachingService) key(input string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	sum := sha256.Sum256([]byte(s.namespace + "\n" + normalized))
+	return "emotion-score:" + hex.EncodeToString(sum[:])
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *CachingService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	key := s.key(input)
+
+	value, ok, err := s.store.GetCacheEntry(ctx, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "error reading cached emotion score", "error", err)
+	} else if ok {
+		if score, err := strconv.Atoi(value); err == nil {
+			metrics.AICacheRequests.WithLabelValues("hit").Inc()
+			return score, nil
+		}
+	}
+	metrics.AICacheRequests.WithLabelValues("miss").Inc()
+
+	score, err := s.AIService.GetEmotionScore(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.store.SetCacheEntry(ctx, key, strconv.Itoa(score), s.ttl); err != nil {
+		slog.ErrorContext(ctx, "error caching emotion score", "error", err)
+	}
+
+	return score, nil
+}
@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/pkg/cache"
+)
+
+func TestCachingService(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	next := &fakeService{}
+	store := cache.NewLRU(10)
+	service := NewCachingService(next, store, "model/v1", time.Hour)
+
+	for _, input := range []string{":smile:", "  :SMILE: ", ":smile:"} {
+		score, err := service.GetEmotionScore(ctx, input)
+		s.NoError(err)
+		s.Equal(50, score)
+	}
+	s.Equal(1, next.calls)
+
+	// Another namespace, e.g. a new prompt version, does not share the scores.
+	_, err := NewCachingService(next, store, "model/v2", time.Hour).GetEmotionScore(ctx, ":smile:")
+	s.NoError(err)
+	s.Equal(2, next.calls)
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.AIService = (*MetricsService)(nil)
+
+// MetricsService observes the latency and errors of the AI service calls.
+type MetricsService struct {
+	next     domain.AIService
+	provider string
+}
+
+// NewMetricsService creates a new MetricsService, the provider labels the metrics, e.g. gemini.
+func NewMetricsService(next domain.AIService, provider string) *MetricsService {
+	return &MetricsService{next: next, provider: provider}
+}
+
+// observe records the call which started at startedAt.
+func (s *MetricsService) observe(method string, startedAt time.Time, err error) {
+	metrics.AIRequestDuration.WithLabelValues(s.provider, method).Observe(time.Since(startedAt).Seconds())
+	if err != nil {
+		metrics.AIRequestErrors.WithLabelValues(s.provider, method).Inc()
+	}
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *MetricsService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	startedAt := time.Now()
+	score, err := s.next.GetEmotionScore(ctx, input)
+	s.observe("GetEmotionScore", startedAt, err)
+	return score, err
+}
+
+// GenerateTaskSuggestion implements domain.AIService.
+func (s *MetricsService) GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error) {
+	startedAt := time.Now()
+	task, err := s.next.GenerateTaskSuggestion(ctx, emoji, description, score)
+	s.observe("GenerateTaskSuggestion", startedAt, err)
+	return task, err
+}
+
+// GenerateDailySummary implements domain.AIService.
+func (s *MetricsService) GenerateDailySummary(ctx context.Context, averageScore float64) (string, error) {
+	startedAt := time.Now()
+	summary, err := s.next.GenerateDailySummary(ctx, averageScore)
+	s.observe("GenerateDailySummary", startedAt, err)
+	return summary, err
+}
+
+// DetectCrisis implements domain.AIService.
+func (s *MetricsService) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	startedAt := time.Now()
+	crisis, err := s.next.DetectCrisis(ctx, input)
+	s.observe("DetectCrisis", startedAt, err)
+	return crisis, err
+}
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is the price in USD per million tokens of a model.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// Pricing maps models to their price.
+type Pricing map[string]Price
+
+// ParsePricing parses prices in the form of <model>=<prompt price>:<completion price>,
+// e.g. gemini-1.5-flash=0.075:0.3.
+func ParsePricing(values []string) (Pricing, error) {
+	pricing := make(Pricing, len(values))
+	for _, value := range values {
+		model, prices, ok := strings.Cut(value, "=")
+		prompt, completion, ok2 := strings.Cut(prices, ":")
+		if !ok || !ok2 || model == "" {
+			return nil, fmt.Errorf("invalid price %q, expected <model>=<prompt price>:<completion price>", value)
+		}
+
+		var price Price
+		var err error
+		if price.Prompt, err = strconv.ParseFloat(prompt, 64); err != nil || price.Prompt < 0 {
+			return nil, fmt.Errorf("invalid prompt price in %q", value)
+		}
+		if price.Completion, err = strconv.ParseFloat(completion, 64); err != nil || price.Completion < 0 {
+			return nil, fmt.Errorf("invalid completion price in %q", value)
+		}
+		pricing[model] = price
+	}
+
+	return pricing, nil
+}
+
+// Cost returns the cost in USD of the tokens, it reports false when the model has no price.
+func (p Pricing) Cost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6, true
+}
@@ -0,0 +1,41 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/pkg/redact"
+)
+
+var _ domain.AIService = (*RedactingService)(nil)
+
+// RedactingService redacts personal information from the text before it is sent to the AI service.
+type RedactingService struct {
+	next     domain.AIService
+	redactor *redact.Redactor
+}
+
+// NewRedactingService creates a new RedactingService.
+func NewRedactingService(next domain.AIService, redactor *redact.Redactor) *RedactingService {
+	return &RedactingService{next: next, redactor: redactor}
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *RedactingService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	return s.next.GetEmotionScore(ctx, s.redactor.Redact(input))
+}
+
+// GenerateTaskSuggestion implements domain.AIService.
+func (s *RedactingService) GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error) {
+	return s.next.GenerateTaskSuggestion(ctx, emoji, s.redactor.Redact(description), score)
+}
+
+// GenerateDailySummary implements domain.AIService. The summary only receives a score, so there is nothing to redact.
+func (s *RedactingService) GenerateDailySummary(ctx context.Context, averageScore float64) (string, error) {
+	return s.next.GenerateDailySummary(ctx, averageScore)
+}
+
+// DetectCrisis implements domain.AIService.
+func (s *RedactingService) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	return s.next.DetectCrisis(ctx, s.redactor.Redact(input))
+}
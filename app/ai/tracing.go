@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var tracer = otel.Tracer("github.com/omegaatt36/cerberus/app/ai")
+
+var _ domain.AIService = (*TracingService)(nil)
+
+// TracingService traces every AI service call as a span. The provider adds the prompt version and
+// token counts to the span of the context, see RecordGeminiUsage.
+type TracingService struct {
+	next     domain.AIService
+	provider string
+	model    string
+}
+
+// NewTracingService creates a new TracingService.
+func NewTracingService(next domain.AIService, provider, model string) *TracingService {
+	return &TracingService{next: next, provider: provider, model: model}
+}
+
+// start starts the span of the method.
+func (s *TracingService) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "ai."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("ai.provider", s.provider),
+		attribute.String("ai.model", s.model),
+		attribute.String("ai.method", method),
+	))
+}
+
+// end ends the span which started at startedAt.
+func (s *TracingService) end(span trace.Span, startedAt time.Time, err error) {
+	span.SetAttributes(attribute.Int64("ai.latency_ms", time.Since(startedAt).Milliseconds()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// GetEmotionScore implements domain.AIService.
+func (s *TracingService) GetEmotionScore(ctx context.Context, input string) (int, error) {
+	ctx, span := s.start(ctx, "GetEmotionScore")
+	startedAt := time.Now()
+	score, err := s.next.GetEmotionScore(ctx, input)
+	s.end(span, startedAt, err)
+	return score, err
+}
+
+// GenerateTaskSuggestion implements domain.AIService.
+func (s *TracingService) GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error) {
+	ctx, span := s.start(ctx, "GenerateTaskSuggestion")
+	startedAt := time.Now()
+	task, err := s.next.GenerateTaskSuggestion(ctx, emoji, description, score)
+	s.end(span, startedAt, err)
+	return task, err
+}
+
+// GenerateDailySummary implements domain.AIService.
+func (s *TracingService) GenerateDailySummary(ctx context.Context, averageScore float64) (string, error) {
+	ctx, span := s.start(ctx, "GenerateDailySummary")
+	startedAt := time.Now()
+	summary, err := s.next.GenerateDailySummary(ctx, averageScore)
+	s.end(span, startedAt, err)
+	return summary, err
+}
+
+// DetectCrisis implements domain.AIService.
+func (s *TracingService) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	ctx, span := s.start(ctx, "DetectCrisis")
+	startedAt := time.Now()
+	crisis, err := s.next.DetectCrisis(ctx, input)
+	s.end(span, startedAt, err)
+	return crisis, err
+}
@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/pkg/gemini"
+)
+
+// UsageRecorder records the token usage reported by the AI provider.
+type UsageRecorder struct {
+	repo domain.AIUsageRepository
+}
+
+// NewUsageRecorder creates a new UsageRecorder, a nil repository only records metrics and traces.
+func NewUsageRecorder(repo domain.AIUsageRepository) *UsageRecorder {
+	return &UsageRecorder{repo: repo}
+}
+
+// RecordGeminiUsage is the gemini.UsageHook which counts the tokens in the metrics, adds the prompt
+// version and token counts to the span of the AI service call, and stores the usage with the actor
+// of the context.
+func (r *UsageRecorder) RecordGeminiUsage(ctx context.Context, method string, usage gemini.Usage) {
+	metrics.AITokens.WithLabelValues("gemini", method, "prompt").Add(float64(usage.PromptTokens))
+	metrics.AITokens.WithLabelValues("gemini", method, "completion").Add(float64(usage.CompletionTokens))
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("ai.prompt_version", usage.PromptVersion),
+		attribute.Int("ai.prompt_tokens", usage.PromptTokens),
+		attribute.Int("ai.completion_tokens", usage.CompletionTokens),
+	)
+
+	if r.repo == nil {
+		return
+	}
+
+	actor, _ := domain.ActorFromContext(ctx)
+	if err := r.repo.CreateAIUsage(ctx, domain.CreateAIUsageRequest{
+		TeamID:           actor.TeamID,
+		UserID:           actor.UserID,
+		Provider:         "gemini",
+		Model:            usage.Model,
+		Method:           method,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	}); err != nil {
+		slog.ErrorContext(ctx, "error recording ai usage", "error", err)
+	}
+}
@@ -15,9 +15,18 @@ import (
 
 // App is cli wrapper that do some common operation and creates signal handler.
 type App struct {
+	Flags    []cli.Flag
+	Before   func(*cli.Context) error
+	After    func(*cli.Context) error
+	Action   func(context.Context)
+	Commands []*Command
+}
+
+// Command is a sub command of App, it shares the flags, Before and After of App.
+type Command struct {
+	Name   string
+	Usage  string
 	Flags  []cli.Flag
-	Before func(*cli.Context) error
-	After  func(*cli.Context) error
 	Action func(context.Context)
 }
 
@@ -54,6 +63,15 @@ func (a *App) Run() {
 	app.After = a.After
 	app.Action = a.action
 
+	for _, command := range a.Commands {
+		app.Commands = append(app.Commands, &cli.Command{
+			Name:   command.Name,
+			Usage:  command.Usage,
+			Flags:  command.Flags,
+			Action: (&App{Action: command.Action}).action,
+		})
+	}
+
 	if err := app.Run(os.Args); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,136 @@
+package cerberus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/omegaatt36/cerberus/app/ai"
+	"github.com/omegaatt36/cerberus/app/export"
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// adminConfig defines the admins and what they can report on.
+type adminConfig struct {
+	userIDs     []string
+	aiUsageRepo domain.AIUsageRepository
+	pricing     ai.Pricing
+}
+
+const adminUsage = "Usage: `/emoji admin usage [all|<n>d|YYYY-MM-DD..YYYY-MM-DD]`"
+
+// isAdmin reports whether the user is one of the configured admins.
+func (b *Bot) isAdmin(userID string) bool {
+	for _, admin := range b.admin.userIDs {
+		if admin == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleAdminCommand handles `/emoji admin <subcommand>`, which is only available to the admins.
+func (b *Bot) handleAdminCommand(ctx context.Context, command *slack.SlashCommand, args string) (string, error) {
+	if !b.isAdmin(command.UserID) {
+		return "Sorry, only admins can use this command.", nil
+	}
+
+	subcommand, args := parseSubcommand(args)
+	switch subcommand {
+	case "usage":
+		return b.handleAdminUsageCommand(ctx, command, args)
+	default:
+		return adminUsage, nil
+	}
+}
+
+// handleAdminUsageCommand reports the AI token usage and cost of the workspace by user, the last 30 days by default.
+func (b *Bot) handleAdminUsageCommand(ctx context.Context, command *slack.SlashCommand, args string) (string, error) {
+	if b.admin.aiUsageRepo == nil {
+		return "AI usage accounting is not enabled.", nil
+	}
+
+	if args == "" {
+		args = "30d"
+	}
+	r, err := export.ParseRange(args, time.Now())
+	if err != nil {
+		return fmt.Sprintf("%s\n%s", err.Error(), adminUsage), nil
+	}
+
+	summaries, err := b.admin.aiUsageRepo.SumAIUsage(ctx, domain.SumAIUsageRequest{
+		TeamID: command.TeamID,
+		Since:  r.Since,
+		Until:  r.Until,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summing ai usage failed: %w", err)
+	}
+
+	if len(summaries) == 0 {
+		return "No AI usage in this period.", nil
+	}
+
+	type userUsage struct {
+		calls, tokens int
+		cost          float64
+	}
+	users := make(map[string]*userUsage)
+	var total userUsage
+	unpriced := make(map[string]struct{})
+	for _, summary := range summaries {
+		usage, ok := users[summary.UserID]
+		if !ok {
+			usage = &userUsage{}
+			users[summary.UserID] = usage
+		}
+
+		cost, ok := b.admin.pricing.Cost(summary.Model, summary.PromptTokens, summary.CompletionTokens)
+		if !ok {
+			unpriced[summary.Model] = struct{}{}
+		}
+
+		tokens := summary.PromptTokens + summary.CompletionTokens
+		for _, u := range []*userUsage{usage, &total} {
+			u.calls += summary.Calls
+			u.tokens += tokens
+			u.cost += cost
+		}
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for userID := range users {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return users[userIDs[i]].tokens > users[userIDs[j]].tokens
+	})
+
+	var sb strings.Builder
+	sb.WriteString("*AI usage of this workspace*\n")
+	for _, userID := range userIDs {
+		usage := users[userID]
+		name := "(no user)"
+		if userID != "" {
+			name = fmt.Sprintf("<@%s>", userID)
+		}
+		fmt.Fprintf(&sb, "• %s: %d calls, %d tokens, $%.4f\n", name, usage.calls, usage.tokens, usage.cost)
+	}
+	fmt.Fprintf(&sb, "*Total*: %d calls, %d tokens, $%.4f", total.calls, total.tokens, total.cost)
+
+	if len(unpriced) > 0 {
+		models := make([]string, 0, len(unpriced))
+		for model := range unpriced {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+		fmt.Fprintf(&sb, "\nModels without a configured price are not included in the cost: %s", strings.Join(models, ", "))
+	}
+
+	return sb.String(), nil
+}
@@ -2,18 +2,29 @@ package cerberus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/omegaatt36/cerberus/app/metrics"
 	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
 )
 
+var tracer = otel.Tracer("github.com/omegaatt36/cerberus/app/cerberus")
+
 // Bot represents the Slack bot with its configuration and dependencies
 type Bot struct {
 	slackBotToken string
@@ -22,8 +33,20 @@ type Bot struct {
 	slackClient  *slack.Client
 	socketClient *socketmode.Client
 
-	emotionRepo domain.EmotionRepository
-	aiService   domain.AIService
+	transactor   domain.Transactor
+	emotionRepo  domain.EmotionRepository
+	auditLogRepo domain.AuditLogRepository
+	buddyRepo    domain.BuddyRepository
+	userDataRepo domain.UserDataRepository
+	aiService    domain.AIService
+
+	safety  safetyConfig
+	buddy   buddyConfig
+	admin   adminConfig
+	limiter *ratelimit.Limiter
+
+	// connected reflects the socket mode connection state observed in handleEvents.
+	connected atomic.Bool
 }
 
 // NewBot creates a new Bot instance.
@@ -31,6 +54,15 @@ func NewBot(slackBotToken, slackAppToken string, options ...Option) *Bot {
 	bot := &Bot{
 		slackBotToken: slackBotToken,
 		slackAppToken: slackAppToken,
+		safety: safetyConfig{
+			keywords:       DefaultCrisisKeywords,
+			supportMessage: DefaultSupportMessage,
+		},
+		buddy: buddyConfig{
+			checkInterval: time.Hour,
+			cooldown:      72 * time.Hour,
+		},
+		transactor: noTransactor{},
 	}
 
 	for _, option := range options {
@@ -43,9 +75,20 @@ func NewBot(slackBotToken, slackAppToken string, options ...Option) *Bot {
 	return bot
 }
 
+// noTransactor runs units of work without a transaction, for a bot without WithTransactorOption.
+type noTransactor struct{}
+
+// WithinTx implements domain.Transactor.
+func (noTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 // Run starts the bot and listens for Slack events
 func (b *Bot) Run(ctx context.Context) {
 	go b.handleEvents(ctx)
+	if b.buddyRepo != nil {
+		go b.runBuddyAlerts(ctx)
+	}
 
 	slog.Info("Starting to listen for Slack events")
 	if err := b.socketClient.RunContext(ctx); err != nil {
@@ -113,7 +156,7 @@ func (b *Bot) handleEmojiCommand(ctx context.Context, command *slack.SlashComman
 
 	emoji, description := parseInput(input)
 	if emoji == "" {
-		return "", fmt.Errorf("please provide a valid emoji at the beginning of your message.\n (e.g., /emoji 😊 Feeling optimistic today!)")
+		return "Please provide a valid emoji at the beginning of your message.\n (e.g., /emoji 😊 Feeling optimistic today!)", nil
 	}
 
 	userID := command.UserID
@@ -121,51 +164,116 @@ func (b *Bot) handleEmojiCommand(ctx context.Context, command *slack.SlashComman
 		return "", fmt.Errorf("can't find user ID")
 	}
 
-	id, err := b.emotionRepo.CreateEmotion(ctx, domain.CreateEmotionRequest{
-		UserID:      userID,
-		Emoji:       emoji,
-		Description: description,
-	})
-	if err != nil {
-		slog.ErrorContext(ctx, "error storing initial data", "error", err)
-		return "", fmt.Errorf("error processing your request, please try again")
+	timeZone := b.userTimeZone(ctx, userID)
+	analysis, reply, analyzeErr := b.analyzeEmotion(ctx, input, emoji, description)
+
+	// The analysis runs first so no transaction waits for the AI service, then the check-in is stored
+	// together with whatever the analysis got to, or not at all.
+	if err := b.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := b.emotionRepo.CreateEmotion(ctx, domain.CreateEmotionRequest{
+			TeamID:      command.TeamID,
+			ChannelID:   command.ChannelID,
+			UserID:      userID,
+			TimeZone:    timeZone,
+			Emoji:       emoji,
+			Description: description,
+		})
+		if err != nil {
+			return fmt.Errorf("error storing initial data: %w", err)
+		}
+
+		if analysis.Score == nil && analysis.Task == nil {
+			return nil
+		}
+
+		if err := b.emotionRepo.UpdateEmotion(ctx, id, analysis); err != nil {
+			return fmt.Errorf("error storing analysis: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("storing check-in failed: %w", err)
+	}
+
+	if analyzeErr != nil {
+		slog.ErrorContext(ctx, "error analyzing check-in", "error", analyzeErr)
+		return "Your check-in is saved, but it could not be analyzed this time, so there is no suggestion.", nil
 	}
 
-	score, err := b.aiService.GetEmotionScore(context.Background(), input)
+	return reply, nil
+}
+
+// userTimeZone returns the IANA time zone of the user from the Slack profile, or an empty string
+// which means UTC when the profile is not available.
+func (b *Bot) userTimeZone(ctx context.Context, userID string) string {
+	user, err := b.socketClient.GetUserInfoContext(ctx, userID)
 	if err != nil {
-		return "", fmt.Errorf("analyzing emotion score failed: %w", err)
+		slog.WarnContext(ctx, "error getting user time zone", "user_id", userID, "error", err)
+		return ""
 	}
 
-	if err := b.emotionRepo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Score: &score}); err != nil {
-		slog.ErrorContext(ctx, "error updating score", "error", err)
+	return user.TZ
+}
+
+// analyzeEmotion scores the input and generates the task suggestion, and returns them as the update of
+// the emotion along with the reply. The update holds the results obtained before an error, if any.
+func (b *Bot) analyzeEmotion(ctx context.Context, input, emoji, description string) (domain.UpdateEmotionRequest, string, error) {
+	var analysis domain.UpdateEmotionRequest
+
+	score, err := b.aiService.GetEmotionScore(ctx, input)
+	if errors.Is(err, domain.ErrAIBudgetExceeded) {
+		return analysis, "Your check-in is saved, but you've used up today's AI budget, so there is no suggestion this time. See you tomorrow! :wave:", nil
+	} else if err != nil {
+		return analysis, "", fmt.Errorf("analyzing emotion score failed: %w", err)
 	}
+	analysis.Score = &score
 
 	task, err := b.aiService.GenerateTaskSuggestion(ctx, emoji, description, score)
-	if err != nil {
-		return "", fmt.Errorf("generating task suggestion failed: %w", err)
+	if errors.Is(err, domain.ErrAIBudgetExceeded) {
+		return analysis, "Your check-in is saved, but you've used up today's AI budget, so there is no suggestion this time. See you tomorrow! :wave:", nil
+	} else if err != nil {
+		return analysis, "", fmt.Errorf("generating task suggestion failed: %w", err)
 	}
+	analysis.Task = &task
 
-	if err := b.emotionRepo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Task: &task}); err != nil {
-		slog.ErrorContext(ctx, "updating task failed", "error", err)
-	}
+	return analysis, task, nil
+}
 
-	return task, nil
+// Connected reports whether the bot is connected to Slack with socket mode.
+func (b *Bot) Connected() bool {
+	return b.connected.Load()
 }
 
 func (b *Bot) handleEvents(ctx context.Context) {
+	var reconnecting bool
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("Context cancelled, stopping event handling")
 			return
 		case event := <-b.socketClient.Events:
+			metrics.SocketModeQueueDepth.Set(float64(len(b.socketClient.Events)))
+
 			switch event.Type {
 			case socketmode.EventTypeConnecting:
 				slog.Info("Connecting to Slack with Socket Mode...")
+				b.connected.Store(false)
 			case socketmode.EventTypeConnectionError:
 				slog.Info("Connection failed. Retrying later...")
+				b.connected.Store(false)
+			case socketmode.EventTypeInvalidAuth:
+				slog.Error("Invalid Slack credentials")
+				b.connected.Store(false)
+			case socketmode.EventTypeDisconnect:
+				slog.Info("Disconnect requested by Slack")
+				b.connected.Store(false)
 			case socketmode.EventTypeConnected:
 				slog.Info("Connected to Slack with Socket Mode.")
+				b.connected.Store(true)
+				if reconnecting {
+					metrics.SocketModeReconnects.Inc()
+				}
+				reconnecting = true
 			case socketmode.EventTypeEventsAPI:
 				eventsAPIEvent, ok := event.Data.(slackevents.EventsAPIEvent)
 				if !ok {
@@ -184,6 +292,16 @@ func (b *Bot) handleEvents(ctx context.Context) {
 				if err := b.handleSlashCommand(cmd); err != nil {
 					slog.Error("Error handling slash command", "error", err)
 				}
+			case socketmode.EventTypeInteractive:
+				callback, ok := event.Data.(slack.InteractionCallback)
+				if !ok {
+					slog.Info("ignored event", "event", event)
+					continue
+				}
+				b.socketClient.Ack(*event.Request)
+				if err := b.handleInteraction(callback); err != nil {
+					slog.Error("Error handling interaction", "error", err)
+				}
 			case socketmode.EventTypeHello:
 				slog.Info("Received hello event from Slack")
 			default:
@@ -193,8 +311,43 @@ func (b *Bot) handleEvents(ctx context.Context) {
 	}
 }
 
-func (b *Bot) handleSlashCommand(command slack.SlashCommand) error {
-	ctx := context.Background()
+// slashCommandSubcommands are the subcommands of /emoji, anything else is a check-in.
+var slashCommandSubcommands = map[string]bool{
+	"buddy":     true,
+	"undo":      true,
+	"export":    true,
+	"forget-me": true,
+	"edit":      true,
+	"admin":     true,
+}
+
+func (b *Bot) handleSlashCommand(command slack.SlashCommand) (err error) {
+	subcommand, args := parseSubcommand(command.Text)
+	label := subcommand
+	if !slashCommandSubcommands[label] {
+		label = "check-in"
+	}
+
+	ctx := domain.WithActor(context.Background(), domain.Actor{TeamID: command.TeamID, UserID: command.UserID})
+	ctx, span := tracer.Start(ctx, "slash_command "+command.Command,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("slack.command", command.Command),
+			attribute.String("slack.subcommand", label),
+			attribute.String("slack.team_id", command.TeamID),
+			attribute.String("slack.channel_id", command.ChannelID),
+		))
+	outcome := "ok"
+	defer func() {
+		if err != nil {
+			outcome = "error"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(attribute.String("slack.outcome", outcome))
+		metrics.SlashCommands.WithLabelValues(command.Command, label, outcome).Inc()
+		span.End()
+	}()
 
 	slog.With(
 		"command", command.Command,
@@ -203,21 +356,164 @@ func (b *Bot) handleSlashCommand(command slack.SlashCommand) error {
 		"channel_id", command.ChannelID,
 	).InfoContext(ctx, "Handling slash command")
 
+	if allowed, retryAfter := b.allow(ctx, command.TeamID, command.UserID); !allowed {
+		// A user in distress may well type quickly, so the keyword check, which costs nothing, still runs.
+		if b.matchesCrisisKeyword(command.Text) {
+			return b.escalate(ctx, &command, safetySourceKeyword)
+		}
+
+		outcome = "rate_limited"
+		return b.sendEphemeral(ctx, command.ChannelID, command.UserID, fmt.Sprintf(
+			"Whoa, slow down a little :turtle: Please try again in %d seconds.", int(retryAfter.Seconds())+1))
+	}
+
 	switch command.Command {
 	case "/emoji":
-		channelID := command.ChannelID
-		if _, _, err := b.socketClient.PostMessageContext(ctx, channelID,
-			slack.MsgOptionText(fmt.Sprintf("<@%s> said: %s", command.UserID, command.Text), false)); err != nil {
-			slog.ErrorContext(ctx, "error sending message", "error", err)
+		switch subcommand {
+		case "buddy":
+			message, err := b.handleBuddyCommand(ctx, &command, args)
+			return b.replyEphemeral(ctx, &command, message, err)
+		case "undo":
+			message, err := b.handleUndoCommand(ctx, command.UserID)
+			return b.replyEphemeral(ctx, &command, message, err)
+		case "export":
+			message, err := b.handleExportCommand(ctx, &command, args)
+			return b.replyEphemeral(ctx, &command, message, err)
+		case "admin":
+			message, err := b.handleAdminCommand(ctx, &command, args)
+			return b.replyEphemeral(ctx, &command, message, err)
+		case "forget-me":
+			return b.handleForgetMeCommand(ctx, &command)
+		case "edit":
+			return b.replyInChannel(ctx, &command, args, "edited their check-in", func() (string, error) {
+				return b.handleEditCommand(ctx, &command, args)
+			})
 		}
-		message, err := b.handleEmojiCommand(ctx, &command)
+
+		return b.replyInChannel(ctx, &command, command.Text, "said", func() (string, error) {
+			return b.handleEmojiCommand(ctx, &command)
+		})
+	default:
+		return fmt.Errorf("unknown command: %s", command.Command)
+	}
+}
+
+func (b *Bot) handleInteraction(callback slack.InteractionCallback) (err error) {
+	ctx := domain.WithActor(context.Background(), domain.Actor{TeamID: callback.Team.ID, UserID: callback.User.ID})
+	ctx, span := tracer.Start(ctx, "interaction "+string(callback.Type),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("slack.interaction_type", string(callback.Type)),
+			attribute.String("slack.callback_id", callback.CallbackID),
+			attribute.String("slack.team_id", callback.Team.ID),
+		))
+	defer func() {
 		if err != nil {
-			return b.sendMessage(ctx, channelID, err.Error())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 		}
-		return b.sendMessage(ctx, channelID, message)
+		span.End()
+	}()
+
+	slog.With(
+		"type", callback.Type,
+		"user_id", callback.User.ID,
+	).InfoContext(ctx, "Handling interaction")
+
+	switch callback.Type {
+	case slack.InteractionTypeMessageAction:
+		switch callback.CallbackID {
+		case callbackIDUndoCheckIn:
+			message, err := b.handleUndoCommand(ctx, callback.User.ID)
+			if err != nil {
+				slog.ErrorContext(ctx, "error handling undo shortcut", "error", err)
+				message = "Something went wrong, please try again later."
+			}
+			return b.sendEphemeral(ctx, callback.Channel.ID, callback.User.ID, message)
+		default:
+			return fmt.Errorf("unknown message shortcut: %s", callback.CallbackID)
+		}
+	case slack.InteractionTypeBlockActions:
+		for _, action := range callback.ActionCallback.BlockActions {
+			switch action.ActionID {
+			case actionIDBuddyAccept, actionIDBuddyDecline:
+				if err := b.handleBuddyConsent(ctx, &callback, action); err != nil {
+					return err
+				}
+			case actionIDForgetMeConfirm, actionIDForgetMeCancel:
+				if err := b.handleForgetMeConfirmation(ctx, &callback, action); err != nil {
+					return err
+				}
+			default:
+				slog.InfoContext(ctx, "ignored block action", "action_id", action.ActionID)
+			}
+		}
+		return nil
 	default:
-		return fmt.Errorf("unknown command: %s", command.Command)
+		return fmt.Errorf("unknown interaction type: %s", callback.Type)
+	}
+}
+
+// allow reports whether the user may run a slash command now, and otherwise how long until they may.
+// Errors of the rate limiter are logged and let the command through.
+func (b *Bot) allow(ctx context.Context, teamID, userID string) (bool, time.Duration) {
+	if b.limiter == nil {
+		return true, 0
+	}
+
+	allowed, retryAfter, err := b.limiter.Allow(ctx, teamID+":"+userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error checking rate limit", "error", err)
+		return true, 0
+	}
+
+	return allowed, retryAfter
+}
+
+// replyInChannel checks the safety of the input and echoes it to the channel,
+// then posts the result of the handler to the channel.
+func (b *Bot) replyInChannel(ctx context.Context, command *slack.SlashCommand, input string, verb string,
+	handle func() (string, error)) error {
+	// Check the safety before anything is echoed to the channel.
+	if source := b.assessSafety(ctx, input); source != "" {
+		return b.escalate(ctx, command, source)
 	}
+
+	channelID := command.ChannelID
+	if _, _, err := b.socketClient.PostMessageContext(ctx, channelID,
+		slack.MsgOptionText(fmt.Sprintf("<@%s> %s: %s", command.UserID, verb, input), false)); err != nil {
+		slog.ErrorContext(ctx, "error sending message", "error", err)
+	}
+
+	message, err := handle()
+	if err != nil {
+		message = "Something went wrong, please try again later."
+		err = fmt.Errorf("handling %s failed: %w", command.Command, err)
+	}
+
+	return errors.Join(err, b.sendMessage(ctx, channelID, message))
+}
+
+// replyEphemeral replies the result of a subcommand to the caller only. The error of the subcommand
+// is replaced by a generic message and returned after the reply is sent.
+func (b *Bot) replyEphemeral(ctx context.Context, command *slack.SlashCommand, message string, err error) error {
+	if err != nil {
+		message = "Something went wrong, please try again later."
+		err = fmt.Errorf("handling %q failed: %w", command.Text, err)
+	}
+
+	return errors.Join(err, b.sendEphemeral(ctx, command.ChannelID, command.UserID, message))
+}
+
+// parseSubcommand splits the command text into the first word and the rest.
+func parseSubcommand(input string) (subcommand string, args string) {
+	subcommand, args, _ = strings.Cut(strings.TrimSpace(input), " ")
+	return subcommand, strings.TrimSpace(args)
+}
+
+func (b *Bot) sendEphemeral(ctx context.Context, channelID, userID, message string) error {
+	_, err := b.socketClient.PostEphemeralContext(ctx, channelID, userID, slack.MsgOptionText(message, false))
+	return err
 }
 
 func (b *Bot) sendMessage(ctx context.Context, channelID string, message string) error {
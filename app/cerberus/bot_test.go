@@ -0,0 +1,170 @@
+package cerberus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+// fakeSlack is a Slack API which records the messages posted by the bot.
+type fakeSlack struct {
+	mu        sync.Mutex
+	messages  []string
+	ephemeral []string
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := map[string]any{"ok": true, "channel": r.Form.Get("channel"), "ts": "1"}
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case "chat.postMessage":
+		f.messages = append(f.messages, r.Form.Get("text"))
+	case "chat.postEphemeral":
+		f.ephemeral = append(f.ephemeral, r.Form.Get("text"))
+	default:
+		response = map[string]any{"ok": false, "error": "not_supported"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// Messages returns the messages posted to channels.
+func (f *fakeSlack) Messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.messages...)
+}
+
+// Ephemeral returns the messages posted to a single user.
+func (f *fakeSlack) Ephemeral() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.ephemeral...)
+}
+
+// newTestBot creates a bot which talks to a fake Slack API.
+func newTestBot(t *testing.T, options ...Option) (*Bot, *fakeSlack) {
+	t.Helper()
+
+	fake := &fakeSlack{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	bot := NewBot("xoxb-test", "xapp-test", options...)
+	bot.slackClient = slack.New(bot.slackBotToken, slack.OptionAPIURL(server.URL+"/"))
+	bot.socketClient = socketmode.New(bot.slackClient)
+
+	return bot, fake
+}
+
+// fakeAIService scores every input the same and never detects a crisis, unless configured otherwise.
+type fakeAIService struct {
+	domain.AIService
+	score     int
+	scoreErr  error
+	task      string
+	crisis    bool
+	crisisErr error
+}
+
+func (f *fakeAIService) GetEmotionScore(context.Context, string) (int, error) {
+	return f.score, f.scoreErr
+}
+
+func (f *fakeAIService) GenerateTaskSuggestion(context.Context, string, string, int) (string, error) {
+	return f.task, nil
+}
+
+func (f *fakeAIService) DetectCrisis(context.Context, string) (bool, error) {
+	return f.crisis, f.crisisErr
+}
+
+// nopEmotionRepository stores nothing.
+type nopEmotionRepository struct {
+	domain.EmotionRepository
+}
+
+func (nopEmotionRepository) CreateEmotion(context.Context, domain.CreateEmotionRequest) (int, error) {
+	return 1, nil
+}
+
+func (nopEmotionRepository) UpdateEmotion(context.Context, int, domain.UpdateEmotionRequest) error {
+	return nil
+}
+
+// failingAnalysisRepository stores check-ins but fails to store their analysis.
+type failingAnalysisRepository struct {
+	*repository.GORMRepository
+}
+
+func (failingAnalysisRepository) UpdateEmotion(context.Context, int, domain.UpdateEmotionRequest) error {
+	return errors.New("disk full")
+}
+
+func TestHandleCheckIn(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		command := slack.SlashCommand{Command: "/emoji", Text: ":smile: lunch with friends", TeamID: "T1", ChannelID: "C1", UserID: "U1"}
+		ai := &fakeAIService{score: 80, task: "take a walk"}
+
+		bot, slackAPI := newTestBot(t, &WithAIServiceOption{AIService: ai},
+			&WithEmotionRepositoryOption{EmotionRepository: repo}, &WithTransactorOption{Transactor: repo})
+		s.NoError(bot.handleSlashCommand(command))
+
+		emotions, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 1)
+		s.Equal(80, *emotions[0].Score)
+		s.Equal("take a walk", emotions[0].Task)
+		s.Contains(slackAPI.Messages(), "take a walk")
+
+		// The check-in is rolled back together with its analysis.
+		bot, slackAPI = newTestBot(t, &WithAIServiceOption{AIService: ai},
+			&WithEmotionRepositoryOption{EmotionRepository: failingAnalysisRepository{repo}}, &WithTransactorOption{Transactor: repo})
+		s.ErrorContains(bot.handleSlashCommand(command), "disk full")
+
+		emotions, err = repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 1)
+		s.Contains(slackAPI.Messages(), "Something went wrong, please try again later.")
+
+		// A bot without a transactor still stores check-ins.
+		bot, _ = newTestBot(t, &WithAIServiceOption{AIService: ai}, &WithEmotionRepositoryOption{EmotionRepository: repo})
+		s.NoError(bot.handleSlashCommand(command))
+
+		emotions, err = repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 2)
+	})
+}
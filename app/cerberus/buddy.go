@@ -0,0 +1,288 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+const (
+	defaultBuddyThreshold = 40
+	defaultBuddyDays      = 3
+	maxBuddyDays          = 14
+
+	actionIDBuddyAccept  = "buddy_accept"
+	actionIDBuddyDecline = "buddy_decline"
+)
+
+// buddyConfig defines how often buddies are evaluated and notified.
+type buddyConfig struct {
+	checkInterval time.Duration
+	cooldown      time.Duration
+}
+
+var slackUserMentionRegexp = regexp.MustCompile(`^<@([A-Z0-9]+)(\|[^>]*)?>$`)
+
+// parseUserMention extracts the user ID from an escaped Slack mention, e.g. <@U123|name>.
+func parseUserMention(s string) string {
+	matches := slackUserMentionRegexp.FindStringSubmatch(s)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	return matches[1]
+}
+
+const buddyUsage = "Usage: `/emoji buddy @someone [threshold 1-100] [days 1-14]` to invite a buddy, or `/emoji buddy off` to stop."
+
+// handleBuddyCommand handles `/emoji buddy @someone [threshold] [days]` and `/emoji buddy off`.
+func (b *Bot) handleBuddyCommand(ctx context.Context, command *slack.SlashCommand, args string) (string, error) {
+	if b.buddyRepo == nil {
+		return "Buddy alerts are not enabled.", nil
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return buddyUsage, nil
+	}
+
+	if fields[0] == "off" {
+		if err := b.buddyRepo.DeleteBuddyByUserID(ctx, command.UserID); err != nil {
+			return "", fmt.Errorf("removing buddy failed: %w", err)
+		}
+		return "Your buddy will no longer be notified.", nil
+	}
+
+	buddyUserID := parseUserMention(fields[0])
+	if buddyUserID == "" {
+		return buddyUsage, nil
+	}
+	if buddyUserID == command.UserID {
+		return "You can't be your own buddy, please pick someone else.", nil
+	}
+
+	threshold, days := defaultBuddyThreshold, defaultBuddyDays
+	if len(fields) > 1 {
+		value, err := strconv.Atoi(fields[1])
+		if err != nil || value < 1 || value > 100 {
+			return buddyUsage, nil
+		}
+		threshold = value
+	}
+	if len(fields) > 2 {
+		value, err := strconv.Atoi(fields[2])
+		if err != nil || value < 1 || value > maxBuddyDays {
+			return buddyUsage, nil
+		}
+		days = value
+	}
+
+	id, err := b.buddyRepo.CreateBuddy(ctx, domain.CreateBuddyRequest{
+		UserID:      command.UserID,
+		BuddyUserID: buddyUserID,
+		Threshold:   threshold,
+		Days:        days,
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating buddy failed: %w", err)
+	}
+
+	invitation := fmt.Sprintf("<@%s> would like you to be their check-in buddy. "+
+		"If their average mood score stays below %d for %d days in a row, I will send you a gentle note so you can reach out. "+
+		"Nothing they write is shared with you.", command.UserID, threshold, days)
+	value := strconv.Itoa(id)
+	if _, _, err := b.socketClient.PostMessageContext(ctx, buddyUserID,
+		slack.MsgOptionText(invitation, false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, invitation, false, false), nil, nil),
+			slack.NewActionBlock("buddy_consent",
+				slack.NewButtonBlockElement(actionIDBuddyAccept, value,
+					slack.NewTextBlockObject(slack.PlainTextType, "Accept", false, false)).WithStyle(slack.StylePrimary),
+				slack.NewButtonBlockElement(actionIDBuddyDecline, value,
+					slack.NewTextBlockObject(slack.PlainTextType, "Decline", false, false)),
+			),
+		)); err != nil {
+		return "", fmt.Errorf("sending buddy invitation failed: %w", err)
+	}
+
+	return fmt.Sprintf("I've asked <@%s> to be your buddy. You'll hear back once they answer.", buddyUserID), nil
+}
+
+// handleBuddyConsent handles the accept and decline buttons of a buddy invitation.
+func (b *Bot) handleBuddyConsent(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	if b.buddyRepo == nil {
+		return nil
+	}
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		return fmt.Errorf("invalid buddy id %q: %w", action.Value, err)
+	}
+
+	buddy, err := b.buddyRepo.GetBuddy(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return b.sendMessage(ctx, callback.Container.ChannelID, "This invitation is no longer valid.")
+	} else if err != nil {
+		return fmt.Errorf("getting buddy failed: %w", err)
+	}
+
+	if buddy.BuddyUserID != callback.User.ID || buddy.Status != domain.BuddyStatusPending {
+		return b.sendMessage(ctx, callback.Container.ChannelID, "This invitation is no longer valid.")
+	}
+
+	status := domain.BuddyStatusDeclined
+	req := domain.UpdateBuddyRequest{Status: &status}
+	reply := "No problem, you declined the invitation."
+	notification := fmt.Sprintf("<@%s> declined to be your buddy.", buddy.BuddyUserID)
+	if action.ActionID == actionIDBuddyAccept {
+		now := time.Now()
+		status = domain.BuddyStatusActive
+		req.ConfirmedAt = &now
+		reply = fmt.Sprintf("Thank you! I'll let you know if <@%s> seems to be having a rough time.", buddy.UserID)
+		notification = fmt.Sprintf("<@%s> is now your buddy.", buddy.BuddyUserID)
+	}
+
+	if err := b.buddyRepo.UpdateBuddy(ctx, id, req); err != nil {
+		return fmt.Errorf("updating buddy failed: %w", err)
+	}
+
+	if _, _, _, err := b.socketClient.UpdateMessageContext(ctx, callback.Container.ChannelID, callback.Container.MessageTs,
+		slack.MsgOptionText(reply, false), slack.MsgOptionBlocks()); err != nil {
+		slog.ErrorContext(ctx, "error updating buddy invitation", "error", err)
+	}
+
+	return b.sendMessage(ctx, buddy.UserID, notification)
+}
+
+// runBuddyAlerts evaluates the buddies periodically until the context is cancelled.
+func (b *Bot) runBuddyAlerts(ctx context.Context) {
+	ticker := time.NewTicker(b.buddy.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.evaluateBuddies(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "error evaluating buddies", "error", err)
+			}
+		}
+	}
+}
+
+// evaluateBuddies notifies the active buddies whose user had a low mood for the configured number of days.
+func (b *Bot) evaluateBuddies(ctx context.Context, now time.Time) error {
+	status := domain.BuddyStatusActive
+	buddies, err := b.buddyRepo.ListBuddies(ctx, domain.ListBuddiesRequest{Status: &status})
+	if err != nil {
+		return err
+	}
+
+	for _, buddy := range buddies {
+		if buddy.LastNotifiedAt != nil && now.Sub(*buddy.LastNotifiedAt) < b.buddy.cooldown {
+			continue
+		}
+
+		// A day longer than the streak covers its first local day in any time zone.
+		lookback := now.AddDate(0, 0, -buddy.Days-1)
+		emotions, err := b.emotionRepo.ListEmotions(ctx, domain.ListEmotionsRequest{
+			UserID: buddy.UserID,
+			Since:  &lookback,
+			Until:  &now,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "error listing emotions", "buddy_id", buddy.ID, "error", err)
+			continue
+		}
+
+		// The days are the local days of the user, in the time zone of their latest check-in.
+		loc := time.UTC
+		if len(emotions) > 0 {
+			loc = location(emotions[len(emotions)-1].TimeZone)
+		}
+		local := now.In(loc)
+		since := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -buddy.Days)
+
+		if !isLowMoodStreak(emotions, since, buddy.Days, buddy.Threshold) {
+			continue
+		}
+
+		message := fmt.Sprintf("Hi! <@%s> seems to have been having a rough few days. "+
+			"Maybe a good moment to say hi or grab a coffee together? :hugging_face:", buddy.UserID)
+		if err := b.sendMessage(ctx, buddy.BuddyUserID, message); err != nil {
+			slog.ErrorContext(ctx, "error notifying buddy", "buddy_id", buddy.ID, "error", err)
+			continue
+		}
+
+		if err := b.buddyRepo.UpdateBuddy(ctx, buddy.ID, domain.UpdateBuddyRequest{LastNotifiedAt: &now}); err != nil {
+			slog.ErrorContext(ctx, "error updating buddy", "buddy_id", buddy.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// isLowMoodStreak reports whether every one of the days starting at the local midnight since has scored
+// check-ins whose average score is below the threshold. Each check-in falls on the day of its own time zone,
+// like in the daily statistics, or of the time zone of since if it has none. Unscored check-ins are skipped,
+// so a day with only unscored check-ins has no data and breaks the streak.
+func isLowMoodStreak(emotions []domain.Emotion, since time.Time, days int, threshold int) bool {
+	sums := make([]int, days)
+	counts := make([]int, days)
+	for _, emotion := range emotions {
+		if emotion.Score == nil {
+			continue
+		}
+
+		loc := since.Location()
+		if emotion.TimeZone != "" {
+			loc = location(emotion.TimeZone)
+		}
+		day := daysBetween(since, emotion.CreatedAt.In(loc))
+		if day < 0 || day >= days {
+			continue
+		}
+		sums[day] += *emotion.Score
+		counts[day]++
+	}
+
+	for day := range days {
+		if counts[day] == 0 || float64(sums[day])/float64(counts[day]) >= float64(threshold) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// daysBetween returns the number of calendar days from the date of from to the date of to, each in its
+// own location, so days of 23 or 25 hours count as one.
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate) / (24 * time.Hour))
+}
+
+// location loads the IANA time zone, unknown or empty time zones fall back to UTC.
+func location(timeZone string) *time.Location {
+	if timeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
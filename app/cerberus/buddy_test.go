@@ -0,0 +1,169 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+func TestIsLowMoodStreak(t *testing.T) {
+	since := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	checkIn := func(day int, hour int, score int) domain.Emotion {
+		return domain.Emotion{CreatedAt: since.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour), Score: &score}
+	}
+
+	unscored := func(day int, hour int) domain.Emotion {
+		return domain.Emotion{CreatedAt: since.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)}
+	}
+
+	tests := []struct {
+		name     string
+		emotions []domain.Emotion
+		want     bool
+	}{
+		{
+			name:     "every day below threshold",
+			emotions: []domain.Emotion{checkIn(0, 9, 20), checkIn(1, 9, 30), checkIn(2, 18, 10)},
+			want:     true,
+		},
+		{
+			name:     "daily average above threshold",
+			emotions: []domain.Emotion{checkIn(0, 9, 20), checkIn(1, 9, 10), checkIn(1, 18, 90), checkIn(2, 9, 10)},
+			want:     false,
+		},
+		{
+			name:     "missing day breaks the streak",
+			emotions: []domain.Emotion{checkIn(0, 9, 20), checkIn(2, 9, 20)},
+			want:     false,
+		},
+		{
+			name:     "unscored check-ins are skipped",
+			emotions: []domain.Emotion{checkIn(0, 9, 30), unscored(0, 12), unscored(0, 15), checkIn(1, 9, 30), checkIn(2, 9, 30), unscored(2, 10)},
+			want:     true,
+		},
+		{
+			name:     "day with only unscored check-ins breaks the streak",
+			emotions: []domain.Emotion{checkIn(0, 9, 20), unscored(1, 9), unscored(1, 18), checkIn(2, 9, 20)},
+			want:     false,
+		},
+		{
+			name:     "check-ins outside the window are ignored",
+			emotions: []domain.Emotion{checkIn(-1, 9, 90), checkIn(0, 9, 20), checkIn(1, 9, 20), checkIn(2, 9, 20), checkIn(3, 9, 90)},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isLowMoodStreak(tt.emotions, since, 3, 40))
+		})
+	}
+}
+
+func TestIsLowMoodStreakLocalDays(t *testing.T) {
+	s := assert.New(t)
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.NoError(err)
+
+	// Check-ins at 07:00 in Taipei are at 23:00 UTC on the day before.
+	since := time.Date(2026, 10, 16, 0, 0, 0, 0, taipei)
+	checkIn := func(day int, score int) domain.Emotion {
+		return domain.Emotion{CreatedAt: since.AddDate(0, 0, day).Add(7 * time.Hour).UTC(), TimeZone: "Asia/Taipei", Score: &score}
+	}
+
+	s.True(isLowMoodStreak([]domain.Emotion{checkIn(0, 20), checkIn(1, 20), checkIn(2, 20)}, since, 3, 40))
+	s.False(isLowMoodStreak([]domain.Emotion{checkIn(-1, 20), checkIn(1, 20), checkIn(2, 20)}, since, 3, 40))
+}
+
+// fakeBuddyRepository lists the buddies, and returns err from GetBuddy.
+type fakeBuddyRepository struct {
+	domain.BuddyRepository
+	buddies []domain.Buddy
+	err     error
+}
+
+func (f fakeBuddyRepository) GetBuddy(context.Context, int) (*domain.Buddy, error) {
+	return nil, f.err
+}
+
+func (f fakeBuddyRepository) ListBuddies(context.Context, domain.ListBuddiesRequest) ([]domain.Buddy, error) {
+	return f.buddies, nil
+}
+
+func (f fakeBuddyRepository) UpdateBuddy(context.Context, int, domain.UpdateBuddyRequest) error {
+	return nil
+}
+
+// fakeEmotionLister lists the emotions within the range of the request.
+type fakeEmotionLister struct {
+	domain.EmotionRepository
+	emotions []domain.Emotion
+}
+
+func (f fakeEmotionLister) ListEmotions(_ context.Context, req domain.ListEmotionsRequest) ([]domain.Emotion, error) {
+	var emotions []domain.Emotion
+	for _, emotion := range f.emotions {
+		if !emotion.CreatedAt.Before(*req.Since) && emotion.CreatedAt.Before(*req.Until) {
+			emotions = append(emotions, emotion)
+		}
+	}
+	return emotions, nil
+}
+
+func TestEvaluateBuddiesLocalDays(t *testing.T) {
+	t.Parallel()
+	s := assert.New(t)
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.NoError(err)
+
+	// Every morning in Taipei is the evening before in UTC, so the streak only shows in local days.
+	var emotions []domain.Emotion
+	for day := 16; day <= 18; day++ {
+		score := 20
+		emotions = append(emotions, domain.Emotion{
+			CreatedAt: time.Date(2026, 10, day, 7, 0, 0, 0, taipei), TimeZone: "Asia/Taipei", Score: &score,
+		})
+	}
+
+	bot, slackAPI := newTestBot(t,
+		&WithBuddyRepositoryOption{BuddyRepository: fakeBuddyRepository{buddies: []domain.Buddy{
+			{ID: 1, UserID: "U1", BuddyUserID: "U2", Threshold: 40, Days: 3, Status: domain.BuddyStatusActive},
+		}}},
+		&WithEmotionRepositoryOption{EmotionRepository: fakeEmotionLister{emotions: emotions}})
+
+	s.NoError(bot.evaluateBuddies(context.Background(), time.Date(2026, 10, 19, 10, 0, 0, 0, taipei)))
+	s.Len(slackAPI.Messages(), 1)
+}
+
+func TestHandleBuddyConsentErrors(t *testing.T) {
+	t.Parallel()
+	s := assert.New(t)
+
+	callback := &slack.InteractionCallback{User: slack.User{ID: "U2"}}
+	callback.Container.ChannelID = "D1"
+	action := &slack.BlockAction{ActionID: actionIDBuddyAccept, Value: "1"}
+
+	bot, slackAPI := newTestBot(t, &WithBuddyRepositoryOption{BuddyRepository: fakeBuddyRepository{err: domain.ErrNotFound}})
+	s.NoError(bot.handleBuddyConsent(context.Background(), callback, action))
+	s.Equal([]string{"This invitation is no longer valid."}, slackAPI.Messages())
+
+	bot, slackAPI = newTestBot(t, &WithBuddyRepositoryOption{BuddyRepository: fakeBuddyRepository{err: errors.New("connection refused")}})
+	s.ErrorContains(bot.handleBuddyConsent(context.Background(), callback, action), "connection refused")
+	s.Empty(slackAPI.Messages())
+}
+
+func TestParseUserMention(t *testing.T) {
+	s := assert.New(t)
+
+	s.Equal("U123ABC", parseUserMention("<@U123ABC|someone>"))
+	s.Equal("U123ABC", parseUserMention("<@U123ABC>"))
+	s.Empty(parseUserMention("@someone"))
+}
@@ -0,0 +1,70 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// callbackIDUndoCheckIn is the callback ID of the message shortcut which deletes the latest check-in.
+const callbackIDUndoCheckIn = "undo_check_in"
+
+// handleEditCommand handles `/emoji edit <emoji> <text>`, which amends the latest check-in of the caller
+// and analyzes it again.
+func (b *Bot) handleEditCommand(ctx context.Context, command *slack.SlashCommand, args string) (string, error) {
+	emoji, description := parseInput(args)
+	if emoji == "" {
+		return "Please provide a valid emoji at the beginning of your message.\n (e.g., /emoji edit :smile: Feeling better now!)", nil
+	}
+
+	// The check-in to edit was usually created moments ago, which a read replica may not have yet.
+	latest, err := b.emotionRepo.GetLatestEmotion(domain.WithReadYourWrites(ctx), command.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "You don't have any check-in to edit yet.", nil
+	} else if err != nil {
+		return "", fmt.Errorf("finding your latest check-in failed: %w", err)
+	}
+
+	// A single update, so the new text is stored together with its analysis. The check-in is left as it
+	// is when the new text cannot be analyzed, as the score and task of the old text would be stale.
+	update, _, err := b.analyzeEmotion(ctx, args, emoji, description)
+	if err != nil {
+		slog.ErrorContext(ctx, "error analyzing edited check-in", "error", err)
+		return "Your check-in is unchanged, as the new text could not be analyzed, please try again later.", nil
+	}
+	if update.Score == nil || update.Task == nil {
+		return "Your check-in is unchanged, as you've used up today's AI budget to analyze the new text. See you tomorrow! :wave:", nil
+	}
+
+	update.Emoji, update.Description, update.Version = &emoji, &description, &latest.Version
+	if err := b.emotionRepo.UpdateEmotion(ctx, latest.ID, update); errors.Is(err, domain.ErrConflict) {
+		return "Your check-in was changed in the meantime, please try again.", nil
+	} else if err != nil {
+		return "", fmt.Errorf("updating your check-in failed: %w", err)
+	}
+
+	return *update.Task, nil
+}
+
+// handleUndoCommand handles `/emoji undo` and the undo message shortcut, which delete the latest check-in
+// of the caller.
+func (b *Bot) handleUndoCommand(ctx context.Context, userID string) (string, error) {
+	latest, err := b.emotionRepo.GetLatestEmotion(domain.WithReadYourWrites(ctx), userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "You don't have any check-in to undo.", nil
+	} else if err != nil {
+		return "", fmt.Errorf("finding latest check-in failed: %w", err)
+	}
+
+	if err := b.emotionRepo.DeleteEmotion(ctx, latest.ID, userID); err != nil {
+		return "", fmt.Errorf("deleting check-in failed: %w", err)
+	}
+
+	return fmt.Sprintf("Your check-in %s from <!date^%d^{date_short_pretty} {time}|%s> has been deleted.",
+		latest.Emoji, latest.CreatedAt.Unix(), latest.CreatedAt.UTC().Format("2006-01-02 15:04 UTC")), nil
+}
@@ -0,0 +1,72 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestEditAndUndo(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		ai := &fakeAIService{score: 30, task: "take a nap"}
+		bot, slackAPI := newTestBot(t, &WithAIServiceOption{AIService: ai},
+			&WithEmotionRepositoryOption{EmotionRepository: repo}, &WithTransactorOption{Transactor: repo})
+		command := func(userID, text string) slack.SlashCommand {
+			return slack.SlashCommand{Command: "/emoji", Text: text, TeamID: "T1", ChannelID: "C1", UserID: userID}
+		}
+
+		s.NoError(bot.handleSlashCommand(command("U1", "undo")))
+		s.NoError(bot.handleSlashCommand(command("U1", "edit :smile: better now")))
+		s.Equal([]string{"You don't have any check-in to undo."}, slackAPI.Ephemeral())
+		s.Contains(slackAPI.Messages(), "You don't have any check-in to edit yet.")
+
+		s.NoError(bot.handleSlashCommand(command("U1", ":cry: rough morning")))
+
+		ai.score, ai.task = 80, "go for a run"
+		s.NoError(bot.handleSlashCommand(command("U1", "edit :smile: better now")))
+
+		latest, err := repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(":smile:", latest.Emoji)
+		s.Equal("better now", latest.Description)
+		s.Equal(80, *latest.Score)
+		s.Equal("go for a run", latest.Task)
+
+		// An edit which cannot be analyzed keeps the check-in with the analysis of its text.
+		ai.scoreErr = errors.New("unavailable")
+		s.NoError(bot.handleSlashCommand(command("U1", "edit :tada: great day")))
+
+		latest, err = repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(":smile:", latest.Emoji)
+		s.Equal(80, *latest.Score)
+
+		// Another user cannot undo the check-in.
+		s.ErrorIs(repo.DeleteEmotion(ctx, latest.ID, "U2"), domain.ErrPermissionDenied)
+		s.NoError(bot.handleSlashCommand(command("U2", "undo")))
+		_, err = repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+
+		s.NoError(bot.handleSlashCommand(command("U1", "undo")))
+		_, err = repo.GetLatestEmotion(ctx, "U1")
+		s.ErrorIs(err, domain.ErrNotFound)
+		s.ErrorIs(repo.DeleteEmotion(ctx, latest.ID, "U1"), domain.ErrNotFound)
+		s.Contains(slackAPI.Ephemeral()[len(slackAPI.Ephemeral())-1], "has been deleted")
+	})
+}
@@ -0,0 +1,85 @@
+package cerberus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/omegaatt36/cerberus/app/export"
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+const exportUsage = "Usage: `/emoji export [csv|json] [all|<n>d|YYYY-MM-DD..YYYY-MM-DD]`"
+
+// handleExportCommand handles `/emoji export [csv|json] [range]`, which uploads the caller's emotions
+// to a direct message.
+func (b *Bot) handleExportCommand(ctx context.Context, command *slack.SlashCommand, args string) (string, error) {
+	now := time.Now()
+	format := export.FormatCSV
+	var r export.Range
+	var formatSet, rangeSet bool
+	for _, field := range strings.Fields(args) {
+		if f, err := export.ParseFormat(field); err == nil && !formatSet {
+			format, formatSet = f, true
+			continue
+		}
+		if parsed, err := export.ParseRange(field, now); err == nil && !rangeSet {
+			r, rangeSet = parsed, true
+			continue
+		}
+
+		return fmt.Sprintf("Unknown argument %q.\n%s", field, exportUsage), nil
+	}
+
+	// Slack needs the size before the upload, so the export is written to a temporary file page by page
+	// instead of into memory.
+	f, err := os.CreateTemp("", "cerberus-export-*")
+	if err != nil {
+		return "", fmt.Errorf("creating export file failed: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	count, err := export.Write(ctx, f, format, b.emotionRepo, domain.ListEmotionsRequest{
+		UserID: command.UserID,
+		Since:  r.Since,
+		Until:  r.Until,
+	})
+	if err != nil {
+		return "", fmt.Errorf("writing export failed: %w", err)
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("writing export failed: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("reading export failed: %w", err)
+	}
+
+	channel, _, _, err := b.socketClient.OpenConversationContext(ctx, &slack.OpenConversationParameters{
+		Users: []string{command.UserID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("opening direct message failed: %w", err)
+	}
+
+	filename := fmt.Sprintf("cerberus-export-%s.%s", now.UTC().Format("20060102"), format)
+	if _, err := b.socketClient.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Reader:         f,
+		FileSize:       int(size),
+		Filename:       filename,
+		Title:          filename,
+		InitialComment: fmt.Sprintf("Here is your export with %d check-ins.", count),
+		Channel:        channel.ID,
+	}); err != nil {
+		return "", fmt.Errorf("uploading export failed: %w", err)
+	}
+
+	return "Your export has been sent to you in a direct message.", nil
+}
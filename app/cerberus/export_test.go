@@ -0,0 +1,23 @@
+package cerberus
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUnknownArgument(t *testing.T) {
+	t.Parallel()
+	s := require.New(t)
+
+	bot, slackAPI := newTestBot(t)
+	for _, text := range []string{"export jsno", "export json 30d 7d"} {
+		s.NoError(bot.handleSlashCommand(slack.SlashCommand{Command: "/emoji", Text: text, ChannelID: "C1", UserID: "U1"}))
+	}
+
+	ephemeral := slackAPI.Ephemeral()
+	s.Len(ephemeral, 2)
+	s.Contains(ephemeral[0], `Unknown argument "jsno"`)
+	s.Contains(ephemeral[1], `Unknown argument "7d"`)
+}
@@ -0,0 +1,60 @@
+package cerberus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	actionIDForgetMeConfirm = "forget_me_confirm"
+	actionIDForgetMeCancel  = "forget_me_cancel"
+)
+
+// handleForgetMeCommand handles `/emoji forget-me` by asking the caller to confirm the purge.
+func (b *Bot) handleForgetMeCommand(ctx context.Context, command *slack.SlashCommand) error {
+	if b.userDataRepo == nil {
+		return b.sendEphemeral(ctx, command.ChannelID, command.UserID, "Deleting your data is not enabled.")
+	}
+
+	const question = "This permanently deletes *all* your check-ins, buddy settings and any other data Cerberus stores about you. " +
+		"It cannot be undone. Are you sure?"
+	_, err := b.socketClient.PostEphemeralContext(ctx, command.ChannelID, command.UserID,
+		slack.MsgOptionText(question, false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, question, false, false), nil, nil),
+			slack.NewActionBlock("forget_me",
+				slack.NewButtonBlockElement(actionIDForgetMeConfirm, command.UserID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Delete everything", false, false)).WithStyle(slack.StyleDanger),
+				slack.NewButtonBlockElement(actionIDForgetMeCancel, command.UserID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false)),
+			),
+		))
+	return err
+}
+
+// handleForgetMeConfirmation handles the buttons of the forget-me confirmation. The purged user is always
+// the one who pressed the button.
+func (b *Bot) handleForgetMeConfirmation(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	reply := "Okay, nothing has been deleted."
+	if action.ActionID == actionIDForgetMeConfirm && b.userDataRepo != nil {
+		result, err := b.userDataRepo.PurgeUser(ctx, callback.User.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "error purging user", "error", err)
+			reply = "Something went wrong and nothing has been deleted, please try again later."
+		} else {
+			var total int64
+			for _, count := range result.DeletedRows {
+				total += count
+			}
+			reply = fmt.Sprintf("Done. %d records about you have been permanently deleted.", total)
+		}
+	}
+
+	_, _, err := b.socketClient.PostMessageContext(ctx, callback.Container.ChannelID,
+		slack.MsgOptionReplaceOriginal(callback.ResponseURL),
+		slack.MsgOptionText(reply, false))
+	return err
+}
@@ -1,7 +1,11 @@
 package cerberus
 
 import (
+	"time"
+
+	"github.com/omegaatt36/cerberus/app/ai"
 	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
 )
 
 // Option defines jwt option.
@@ -26,3 +30,104 @@ type WithEmotionRepositoryOption struct {
 func (o *WithEmotionRepositoryOption) apply(bot *Bot) {
 	bot.emotionRepo = o.EmotionRepository
 }
+
+// WithTransactorOption defines the option to set Transactor, which stores check-ins atomically.
+// Without it, the check-in and its analysis are stored one after the other.
+type WithTransactorOption struct {
+	Transactor domain.Transactor
+}
+
+func (o *WithTransactorOption) apply(bot *Bot) {
+	bot.transactor = o.Transactor
+}
+
+// WithAuditLogRepositoryOption defines the option to set AuditLogRepository.
+type WithAuditLogRepositoryOption struct {
+	AuditLogRepository domain.AuditLogRepository
+}
+
+func (o *WithAuditLogRepositoryOption) apply(bot *Bot) {
+	bot.auditLogRepo = o.AuditLogRepository
+}
+
+// WithSafetyOption defines the option to configure the safety escalation.
+// Empty fields keep the defaults.
+type WithSafetyOption struct {
+	Keywords          []string
+	SupportMessage    string
+	NotifyUserGroupID string
+}
+
+func (o *WithSafetyOption) apply(bot *Bot) {
+	if len(o.Keywords) > 0 {
+		bot.safety.keywords = o.Keywords
+	}
+	if o.SupportMessage != "" {
+		bot.safety.supportMessage = o.SupportMessage
+	}
+	bot.safety.notifyUserGroupID = o.NotifyUserGroupID
+}
+
+// WithBuddyRepositoryOption defines the option to set BuddyRepository, which enables buddy alerts.
+type WithBuddyRepositoryOption struct {
+	BuddyRepository domain.BuddyRepository
+}
+
+func (o *WithBuddyRepositoryOption) apply(bot *Bot) {
+	bot.buddyRepo = o.BuddyRepository
+}
+
+// WithBuddyAlertOption defines the option to configure buddy alerts.
+// Zero values keep the defaults.
+type WithBuddyAlertOption struct {
+	CheckInterval time.Duration
+	Cooldown      time.Duration
+}
+
+func (o *WithBuddyAlertOption) apply(bot *Bot) {
+	if o.CheckInterval > 0 {
+		bot.buddy.checkInterval = o.CheckInterval
+	}
+	if o.Cooldown > 0 {
+		bot.buddy.cooldown = o.Cooldown
+	}
+}
+
+// WithUserDataRepositoryOption defines the option to set UserDataRepository, which enables `/emoji forget-me`.
+type WithUserDataRepositoryOption struct {
+	UserDataRepository domain.UserDataRepository
+}
+
+func (o *WithUserDataRepositoryOption) apply(bot *Bot) {
+	bot.userDataRepo = o.UserDataRepository
+}
+
+// WithAdminOption defines the option to set the admins, who can use `/emoji admin`.
+type WithAdminOption struct {
+	UserIDs []string
+}
+
+func (o *WithAdminOption) apply(bot *Bot) {
+	bot.admin.userIDs = o.UserIDs
+}
+
+// WithAIUsageOption defines the option to set AIUsageRepository and the pricing of the models,
+// which enables `/emoji admin usage`.
+type WithAIUsageOption struct {
+	AIUsageRepository domain.AIUsageRepository
+	Pricing           ai.Pricing
+}
+
+func (o *WithAIUsageOption) apply(bot *Bot) {
+	bot.admin.aiUsageRepo = o.AIUsageRepository
+	bot.admin.pricing = o.Pricing
+}
+
+// WithRateLimiterOption defines the option to limit the slash commands of each user.
+type WithRateLimiterOption struct {
+	Limiter *ratelimit.Limiter
+}
+
+func (o *WithRateLimiterOption) apply(bot *Bot) {
+	bot.limiter = o.Limiter
+}
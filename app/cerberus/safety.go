@@ -0,0 +1,143 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// DefaultCrisisKeywords is the default keyword list which triggers the safety escalation
+// without asking the AI service.
+var DefaultCrisisKeywords = []string{
+	"suicide",
+	"suicidal",
+	"kill myself",
+	"end my life",
+	"want to die",
+	"self-harm",
+	"self harm",
+	"hurt myself",
+	"自殺",
+	"輕生",
+	"想死",
+	"不想活",
+	"自殘",
+	"結束生命",
+}
+
+// DefaultSupportMessage is the default reply to a message which triggers the safety escalation.
+const DefaultSupportMessage = `聽起來你現在真的很辛苦，謝謝你願意說出來，你並不孤單。
+如果你有立即的危險，請撥打 119 或 110。
+想找人聊聊的話，可以撥打安心專線 1925（24 小時）、生命線 1995 或張老師專線 1980。
+It sounds like you are going through a really hard time. If you are in immediate danger, please contact your local emergency services.`
+
+const (
+	safetySourceKeyword = "keyword"
+	safetySourceAI      = "ai"
+)
+
+// safetyConfig defines how the bot handles messages that may indicate a crisis.
+type safetyConfig struct {
+	keywords          []string
+	supportMessage    string
+	notifyUserGroupID string
+}
+
+// assessSafety checks the input against the keyword list first, then asks the AI service.
+// It returns the source which flagged the input, or an empty string if the input is considered safe.
+func (b *Bot) assessSafety(ctx context.Context, input string) string {
+	if strings.TrimSpace(input) == "" {
+		return ""
+	}
+
+	if b.matchesCrisisKeyword(input) {
+		return safetySourceKeyword
+	}
+
+	crisis, err := b.aiService.DetectCrisis(ctx, input)
+	if err != nil {
+		slog.ErrorContext(ctx, "error detecting crisis, only the keyword list checked the input", "error", err)
+		metrics.SafetyChecksSkipped.Inc()
+		return ""
+	}
+
+	if crisis {
+		return safetySourceAI
+	}
+
+	return ""
+}
+
+// matchesCrisisKeyword reports whether the input contains any of the crisis keywords.
+func (b *Bot) matchesCrisisKeyword(input string) bool {
+	normalized := strings.ToLower(input)
+	for _, keyword := range b.safety.keywords {
+		if keyword != "" && strings.Contains(normalized, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// escalate replies to the user with support resources instead of the humorous suggestion,
+// notifies the configured user group and records the escalation in the audit log.
+func (b *Bot) escalate(ctx context.Context, command *slack.SlashCommand, source string) error {
+	slog.WarnContext(ctx, "safety escalation triggered", "user_id", command.UserID, "source", source)
+	metrics.SafetyEscalations.WithLabelValues(source).Inc()
+
+	var errs []error
+	if b.auditLogRepo != nil {
+		if _, err := b.auditLogRepo.CreateAuditLog(ctx, domain.CreateAuditLogRequest{
+			Action: domain.AuditActionSafetyEscalation,
+			UserID: command.UserID,
+			Detail: fmt.Sprintf("source=%s channel_id=%s notified_user_group=%s",
+				source, command.ChannelID, b.safety.notifyUserGroupID),
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("recording safety escalation failed: %w", err))
+		}
+	}
+
+	if _, err := b.socketClient.PostEphemeralContext(ctx, command.ChannelID, command.UserID,
+		slack.MsgOptionText(b.safety.supportMessage, false)); err != nil {
+		errs = append(errs, fmt.Errorf("sending support resources failed: %w", err))
+	}
+
+	if err := b.notifyUserGroup(ctx, command); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyUserGroup sends a direct message to every member of the configured wellbeing user group.
+// The original message is deliberately not forwarded.
+func (b *Bot) notifyUserGroup(ctx context.Context, command *slack.SlashCommand) error {
+	if b.safety.notifyUserGroupID == "" {
+		return nil
+	}
+
+	members, err := b.socketClient.GetUserGroupMembersContext(ctx, b.safety.notifyUserGroupID)
+	if err != nil {
+		return fmt.Errorf("getting user group members failed: %w", err)
+	}
+
+	message := fmt.Sprintf("<@%s> may need some support right now. Their check-in in <#%s> triggered the safety escalation, please reach out to them privately.",
+		command.UserID, command.ChannelID)
+
+	var errs []error
+	for _, member := range members {
+		if _, _, err := b.socketClient.PostMessageContext(ctx, member, slack.MsgOptionText(message, false)); err != nil {
+			errs = append(errs, fmt.Errorf("notifying %s failed: %w", member, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
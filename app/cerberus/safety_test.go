@@ -0,0 +1,65 @@
+package cerberus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/app/ai"
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+)
+
+func TestSafetyBypassesRateLimit(t *testing.T) {
+	t.Parallel()
+	s := require.New(t)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Every(time.Hour, 1))
+	bot, slackAPI := newTestBot(t, &WithAIServiceOption{AIService: &fakeAIService{}},
+		&WithEmotionRepositoryOption{EmotionRepository: nopEmotionRepository{}}, &WithRateLimiterOption{Limiter: limiter})
+
+	command := slack.SlashCommand{Command: "/emoji", TeamID: "T1", ChannelID: "C1", UserID: "U1"}
+	for _, text := range []string{":smile: fine", ":cry: I want to die", ":smile: fine again"} {
+		command.Text = text
+		s.NoError(bot.handleSlashCommand(command))
+	}
+
+	ephemeral := slackAPI.Ephemeral()
+	s.Len(ephemeral, 2)
+	s.Equal(DefaultSupportMessage, ephemeral[0])
+	s.Contains(ephemeral[1], "slow down")
+}
+
+func TestSafetyWhileCircuitOpen(t *testing.T) {
+	t.Parallel()
+	s := require.New(t)
+	ctx := context.Background()
+
+	provider := &fakeAIService{scoreErr: errors.New("unavailable"), crisis: true}
+	breaker := ai.NewCircuitBreakerService(provider, 1, time.Hour)
+	bot, slackAPI := newTestBot(t, &WithAIServiceOption{AIService: breaker})
+
+	_, err := breaker.GetEmotionScore(ctx, "")
+	s.Error(err)
+	s.Equal(ai.CircuitOpen, breaker.State())
+
+	s.NoError(bot.handleSlashCommand(slack.SlashCommand{Command: "/emoji", Text: ":cry: everything is too much",
+		TeamID: "T1", ChannelID: "C1", UserID: "U1"}))
+	s.Equal([]string{DefaultSupportMessage}, slackAPI.Ephemeral())
+}
+
+// TestSafetyCheckSkipped is not parallel, as it reads a global counter.
+func TestSafetyCheckSkipped(t *testing.T) {
+	s := require.New(t)
+
+	bot, _ := newTestBot(t, &WithAIServiceOption{AIService: &fakeAIService{crisisErr: errors.New("unavailable")}})
+
+	skipped := testutil.ToFloat64(metrics.SafetyChecksSkipped)
+	s.Empty(bot.assessSafety(context.Background(), ":cry: rough day"))
+	s.Equal(skipped+1, testutil.ToFloat64(metrics.SafetyChecksSkipped))
+}
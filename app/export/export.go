@@ -0,0 +1,222 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// Format defines the file format of an export.
+type Format string
+
+const (
+	// FormatCSV exports emotions as comma separated values with a header row.
+	FormatCSV Format = "csv"
+	// FormatJSON exports emotions as a JSON array.
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses the format, an empty string means csv.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected csv or json", s)
+	}
+}
+
+// Range defines the time range of an export, nil means unbounded.
+type Range struct {
+	Since *time.Time
+	Until *time.Time
+}
+
+const dateLayout = "2006-01-02"
+
+// ParseRange parses the range relative to now. Supported forms are "all" (or empty),
+// "<n>d" for the last n days, and "YYYY-MM-DD..YYYY-MM-DD" where both ends are inclusive dates in UTC.
+func ParseRange(s string, now time.Time) (Range, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "all" {
+		return Range{}, nil
+	}
+
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		var r Range
+		if from != "" {
+			since, err := time.Parse(dateLayout, from)
+			if err != nil {
+				return Range{}, fmt.Errorf("invalid range start %q: %w", from, err)
+			}
+			r.Since = &since
+		}
+		if to != "" {
+			until, err := time.Parse(dateLayout, to)
+			if err != nil {
+				return Range{}, fmt.Errorf("invalid range end %q: %w", to, err)
+			}
+			until = until.AddDate(0, 0, 1)
+			r.Until = &until
+		}
+		return r, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return Range{}, fmt.Errorf("invalid number of days %q", days)
+		}
+		since := now.AddDate(0, 0, -n)
+		return Range{Since: &since}, nil
+	}
+
+	return Range{}, fmt.Errorf("invalid range %q, expected all, <n>d or YYYY-MM-DD..YYYY-MM-DD", s)
+}
+
+// Record is the exported representation of an emotion.
+type Record struct {
+	CreatedAt       time.Time  `json:"created_at"`
+	Emoji           string     `json:"emoji"`
+	Description     string     `json:"description"`
+	Score           *int       `json:"score"`
+	Task            string     `json:"task"`
+	TaskCompletedAt *time.Time `json:"task_completed_at"`
+}
+
+func newRecord(emotion domain.Emotion) Record {
+	return Record{
+		CreatedAt:       emotion.CreatedAt,
+		Emoji:           emotion.Emoji,
+		Description:     emotion.Description,
+		Score:           emotion.Score,
+		Task:            emotion.Task,
+		TaskCompletedAt: emotion.TaskCompletedAt,
+	}
+}
+
+// Lister lists emotions, like domain.EmotionRepository.
+type Lister interface {
+	ListEmotions(ctx context.Context, req domain.ListEmotionsRequest) ([]domain.Emotion, error)
+}
+
+// pageSize is the number of emotions read at once, so an export never holds all of them in memory.
+const pageSize = 500
+
+// Write writes the emotions of the request to w in the format, reading and writing them a page at a time.
+// It returns the number of written emotions.
+func Write(ctx context.Context, w io.Writer, format Format, lister Lister, req domain.ListEmotionsRequest) (int, error) {
+	var enc encoder
+	switch format {
+	case FormatCSV:
+		enc = &csvEncoder{writer: csv.NewWriter(w)}
+	case FormatJSON:
+		enc = &jsonEncoder{w: w}
+	default:
+		return 0, fmt.Errorf("unknown format %q", format)
+	}
+
+	if err := enc.begin(); err != nil {
+		return 0, err
+	}
+
+	var count int
+	req.Limit = pageSize
+	for {
+		emotions, err := lister.ListEmotions(ctx, req)
+		if err != nil {
+			return count, fmt.Errorf("listing emotions failed: %w", err)
+		}
+
+		for _, emotion := range emotions {
+			if err := enc.write(newRecord(emotion)); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(emotions) < pageSize {
+			return count, enc.end()
+		}
+		req.After = &emotions[len(emotions)-1]
+	}
+}
+
+// encoder writes records one at a time between begin and end.
+type encoder interface {
+	begin() error
+	write(record Record) error
+	end() error
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.writer.Write([]string{"created_at", "emoji", "description", "score", "task", "task_completed_at"})
+}
+
+func (e *csvEncoder) write(record Record) error {
+	var score, taskCompletedAt string
+	if record.Score != nil {
+		score = strconv.Itoa(*record.Score)
+	}
+	if record.TaskCompletedAt != nil {
+		taskCompletedAt = record.TaskCompletedAt.Format(time.RFC3339)
+	}
+
+	return e.writer.Write([]string{
+		record.CreatedAt.Format(time.RFC3339),
+		record.Emoji,
+		record.Description,
+		score,
+		record.Task,
+		taskCompletedAt,
+	})
+}
+
+func (e *csvEncoder) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonEncoder) write(record Record) error {
+	separator := ",\n"
+	if e.count == 0 {
+		separator = "\n"
+	}
+	e.count++
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(e.w, separator+string(b))
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
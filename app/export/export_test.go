@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	daysAgo := func(n int) *time.Time {
+		d := now.AddDate(0, 0, -n)
+		return &d
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Range
+		wantErr bool
+	}{
+		{name: "empty is all", input: "", want: Range{}},
+		{name: "all", input: " ALL ", want: Range{}},
+		{name: "last days", input: "30d", want: Range{Since: daysAgo(30)}},
+		{name: "dates are inclusive", input: "2026-01-01..2026-03-31", want: Range{Since: date(2026, 1, 1), Until: date(2026, 4, 1)}},
+		{name: "open start", input: "..2026-03-31", want: Range{Until: date(2026, 4, 1)}},
+		{name: "open end", input: "2026-01-01..", want: Range{Since: date(2026, 1, 1)}},
+		{name: "zero days", input: "0d", wantErr: true},
+		{name: "negative days", input: "-3d", wantErr: true},
+		{name: "invalid date", input: "2026-13-01..2026-12-31", wantErr: true},
+		{name: "unknown", input: "jsno", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRange(tt.input, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "", want: FormatCSV},
+		{input: "CSV", want: FormatCSV},
+		{input: "json", want: FormatJSON},
+		{input: "jsno", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeLister lists the emotions page by page like the repository, and counts the pages.
+type fakeLister struct {
+	emotions []domain.Emotion
+	pages    int
+}
+
+func (f *fakeLister) ListEmotions(_ context.Context, req domain.ListEmotionsRequest) ([]domain.Emotion, error) {
+	f.pages++
+
+	start := 0
+	if req.After != nil {
+		start = slices.IndexFunc(f.emotions, func(e domain.Emotion) bool { return e.ID == req.After.ID }) + 1
+	}
+	end := len(f.emotions)
+	if req.Limit > 0 {
+		end = min(end, start+req.Limit)
+	}
+
+	return f.emotions[start:end], nil
+}
+
+func newFakeLister(n int) *fakeLister {
+	createdAt := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	score := 80
+	lister := &fakeLister{}
+	for i := range n {
+		lister.emotions = append(lister.emotions, domain.Emotion{
+			ID:          i + 1,
+			CreatedAt:   createdAt.Add(time.Duration(i) * time.Minute),
+			Emoji:       ":smile:",
+			Description: `said "hi", then left`,
+			Score:       &score,
+		})
+	}
+
+	return lister
+}
+
+func TestWriteCSV(t *testing.T) {
+	s := require.New(t)
+
+	for _, n := range []int{0, pageSize, pageSize + 1} {
+		lister := newFakeLister(n)
+		var buf bytes.Buffer
+		count, err := Write(context.Background(), &buf, FormatCSV, lister, domain.ListEmotionsRequest{})
+		s.NoError(err)
+		s.Equal(n, count)
+		s.Equal(n/pageSize+1, lister.pages)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		s.NoError(err)
+		s.Len(records, n+1)
+		s.Equal([]string{"created_at", "emoji", "description", "score", "task", "task_completed_at"}, records[0])
+		if n > 0 {
+			s.Equal([]string{"2026-01-01T09:00:00Z", ":smile:", `said "hi", then left`, "80", "", ""}, records[1])
+			s.Equal(lister.emotions[n-1].CreatedAt.Format(time.RFC3339), records[n][0])
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	s := require.New(t)
+
+	for _, n := range []int{0, pageSize, pageSize + 1} {
+		lister := newFakeLister(n)
+		if n > 0 {
+			lister.emotions[0].Score = nil
+			lister.emotions[0].Task = "<b> </b>"
+		}
+
+		var buf bytes.Buffer
+		count, err := Write(context.Background(), &buf, FormatJSON, lister, domain.ListEmotionsRequest{})
+		s.NoError(err)
+		s.Equal(n, count)
+
+		var records []Record
+		s.NoError(json.Unmarshal(buf.Bytes(), &records))
+		s.Len(records, n)
+		if n > 0 {
+			s.Nil(records[0].Score)
+			s.Equal("<b> </b>", records[0].Task)
+			s.Equal(`said "hi", then left`, records[n-1].Description)
+			s.Equal(lister.emotions[n-1].CreatedAt, records[n-1].CreatedAt)
+		}
+	}
+
+	_, err := Write(context.Background(), &bytes.Buffer{}, Format("xml"), newFakeLister(1), domain.ListEmotionsRequest{})
+	s.Error(err)
+}
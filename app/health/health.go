@@ -0,0 +1,82 @@
+// Package health serves the liveness and readiness endpoints.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Check reports an error when the dependency is not ready.
+type Check func(ctx context.Context) error
+
+// Checker runs the readiness checks by name.
+type Checker struct {
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+// NewChecker creates a new Checker, every check is given the timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{checks: make(map[string]Check), timeout: timeout}
+}
+
+// Add adds a readiness check.
+func (c *Checker) Add(name string, check Check) {
+	c.checks[name] = check
+}
+
+// response is the body of the readiness endpoint, each check is "ok" or its error.
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Run runs every check, and reports whether all of them passed with the result of each check.
+func (c *Checker) Run(ctx context.Context) (bool, map[string]string) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ready := true
+	results := make(map[string]string, len(c.checks))
+	for name, check := range c.checks {
+		if err := check(ctx); err != nil {
+			ready = false
+			results[name] = err.Error()
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	return ready, results
+}
+
+// ReadinessHandler responds 200 when every check passed, otherwise 503.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ready, results := c.Run(r.Context())
+
+		resp := response{Status: "ok", Checks: results}
+		status := http.StatusOK
+		if !ready {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			slog.ErrorContext(r.Context(), "error writing readiness", "error", err)
+		}
+	})
+}
+
+// LivenessHandler responds 200 as long as the process is able to serve HTTP.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
@@ -0,0 +1,42 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/app/health"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	s := require.New(t)
+
+	var slackErr error
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("slack", func(context.Context) error { return slackErr })
+
+	serve := func() (int, map[string]any) {
+		recorder := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var body map[string]any
+		s.NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+		return recorder.Code, body
+	}
+
+	code, body := serve()
+	s.Equal(http.StatusOK, code)
+	s.Equal("ok", body["status"])
+
+	slackErr = errors.New("not connected")
+	code, body = serve()
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(map[string]any{"database": "ok", "slack": "not connected"}, body["checks"])
+}
@@ -0,0 +1,30 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestGORMPlugin(t *testing.T) {
+	s := require.New(t)
+
+	d, finalize := database.TestingInitialize(database.SQLiteOpt)
+	defer finalize()
+
+	db := d.GetDB()
+	s.NoError(repository.NewGORMRepository(db).AutoMigrate())
+	s.NoError(db.Use(metrics.GORMPlugin{}))
+
+	s.NoError(db.Create(&repository.AuditLog{Action: "test"}).Error)
+	var logs []repository.AuditLog
+	s.NoError(db.Find(&logs).Error)
+
+	// One series for each operation and table.
+	s.Equal(2, testutil.CollectAndCount(metrics.DBQueryDuration))
+}
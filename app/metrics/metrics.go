@@ -0,0 +1,112 @@
+// Package metrics defines the Prometheus metrics of Cerberus.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cerberus"
+
+// Registry is the registry of every Cerberus metric, including the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// SlashCommands counts the handled slash commands by subcommand and outcome.
+	SlashCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slash_commands_total",
+		Help:      "Number of handled slash commands by outcome.",
+	}, []string{"command", "subcommand", "outcome"})
+
+	// SafetyEscalations counts the safety escalations by the source which flagged the input.
+	SafetyEscalations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "safety_escalations_total",
+		Help:      "Number of safety escalations by source.",
+	}, []string{"source"})
+
+	// SafetyChecksSkipped counts the inputs which the AI service could not check for a crisis, so only
+	// the keyword list checked them.
+	SafetyChecksSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "safety_checks_skipped_total",
+		Help:      "Number of inputs which were not checked by the AI service for a crisis.",
+	})
+
+	// AIRequestDuration observes the latency of AI service calls.
+	AIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ai_request_duration_seconds",
+		Help:      "Latency of AI service calls.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	}, []string{"provider", "method"})
+
+	// AIRequestErrors counts the failed AI service calls.
+	AIRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_request_errors_total",
+		Help:      "Number of failed AI service calls.",
+	}, []string{"provider", "method"})
+
+	// AITokens counts the tokens used by AI service calls, type is prompt or completion.
+	AITokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_tokens_total",
+		Help:      "Number of tokens used by AI service calls.",
+	}, []string{"provider", "method", "type"})
+
+	// AICacheRequests counts the cache lookups of AI service calls, result is hit or miss.
+	AICacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_cache_requests_total",
+		Help:      "Number of cache lookups of AI service calls by result.",
+	}, []string{"result"})
+
+	// DBQueryDuration observes the latency of database queries.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "table"})
+
+	// SocketModeReconnects counts the reconnections of the Slack socket mode client.
+	SocketModeReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "socketmode_reconnects_total",
+		Help:      "Number of Slack socket mode reconnections.",
+	})
+
+	// SocketModeQueueDepth is the number of Slack events waiting to be handled.
+	SocketModeQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "socketmode_queue_depth",
+		Help:      "Number of Slack socket mode events waiting to be handled.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		SlashCommands,
+		SafetyEscalations,
+		SafetyChecksSkipped,
+		AIRequestDuration,
+		AIRequestErrors,
+		AITokens,
+		AICacheRequests,
+		DBQueryDuration,
+		SocketModeReconnects,
+		SocketModeQueueDepth,
+	)
+}
+
+// Handler returns the HTTP handler which exposes the metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
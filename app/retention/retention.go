@@ -0,0 +1,190 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+const (
+	// ModeDelete deletes emotions past the retention.
+	ModeDelete = "delete"
+	// ModeAnonymize keeps emotions past the retention without the user and personal content.
+	ModeAnonymize = "anonymize"
+)
+
+// Config defines the retention policy from cli flags.
+type Config struct {
+	DefaultDays        int
+	Mode               string
+	WorkspaceOverrides cli.StringSlice
+	Interval           time.Duration
+}
+
+// CliFlags returns cli flag list.
+func (c *Config) CliFlags() []cli.Flag {
+	var flags []cli.Flag
+	flags = append(flags, &cli.IntFlag{
+		Name:        "retention-days",
+		Usage:       "days to keep emotions, 0 keeps them forever",
+		EnvVars:     []string{"RETENTION_DAYS"},
+		Destination: &c.DefaultDays,
+	})
+	flags = append(flags, &cli.StringSliceFlag{
+		Name:        "retention-workspace-days",
+		Usage:       "per workspace override of retention-days, e.g. T0123456789=30",
+		EnvVars:     []string{"RETENTION_WORKSPACE_DAYS"},
+		Destination: &c.WorkspaceOverrides,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "retention-mode",
+		Usage:       "[delete|anonymize]",
+		EnvVars:     []string{"RETENTION_MODE"},
+		Value:       ModeDelete,
+		Destination: &c.Mode,
+	})
+	flags = append(flags, &cli.DurationFlag{
+		Name:        "retention-interval",
+		Usage:       "how often the bot prunes emotions past the retention",
+		EnvVars:     []string{"RETENTION_INTERVAL"},
+		Value:       24 * time.Hour,
+		Destination: &c.Interval,
+	})
+
+	return flags
+}
+
+// Policy parses the config into a policy.
+func (c *Config) Policy() (Policy, error) {
+	policy := Policy{
+		DefaultDays:   c.DefaultDays,
+		WorkspaceDays: make(map[string]int),
+	}
+
+	switch c.Mode {
+	case ModeDelete:
+	case ModeAnonymize:
+		policy.Anonymize = true
+	default:
+		return Policy{}, fmt.Errorf("unknown retention mode %q", c.Mode)
+	}
+
+	for _, override := range c.WorkspaceOverrides.Value() {
+		teamID, days, ok := strings.Cut(override, "=")
+		if !ok || teamID == "" {
+			return Policy{}, fmt.Errorf("invalid workspace retention %q, expected <team ID>=<days>", override)
+		}
+
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return Policy{}, fmt.Errorf("invalid workspace retention %q, expected <team ID>=<days>", override)
+		}
+		policy.WorkspaceDays[teamID] = n
+	}
+
+	return policy, nil
+}
+
+// Policy defines how long emotions are kept, 0 days keeps them forever.
+type Policy struct {
+	DefaultDays   int
+	WorkspaceDays map[string]int
+	Anonymize     bool
+}
+
+// Enabled reports whether the policy prunes anything.
+func (p Policy) Enabled() bool {
+	if p.DefaultDays > 0 {
+		return true
+	}
+
+	for _, days := range p.WorkspaceDays {
+		if days > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pruner prunes emotions past the retention policy.
+type Pruner struct {
+	repo   domain.RetentionRepository
+	policy Policy
+}
+
+// NewPruner creates a new Pruner.
+func NewPruner(repo domain.RetentionRepository, policy Policy) *Pruner {
+	return &Pruner{repo: repo, policy: policy}
+}
+
+// cutoff returns the start of the UTC day which is days before now, the emotions of the days before its
+// date are pruned.
+func cutoff(now time.Time, days int) time.Time {
+	return now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -days)
+}
+
+// Prune prunes the emotions past the retention once, and returns the number of pruned emotions.
+func (p *Pruner) Prune(ctx context.Context, now time.Time) (int64, error) {
+	var pruned int64
+
+	overridden := make([]string, 0, len(p.policy.WorkspaceDays))
+	for teamID, days := range p.policy.WorkspaceDays {
+		overridden = append(overridden, teamID)
+		if days == 0 {
+			continue
+		}
+
+		n, err := p.repo.PruneEmotions(ctx, domain.PruneEmotionsRequest{
+			Before:    cutoff(now, days),
+			TeamIDs:   []string{teamID},
+			Anonymize: p.policy.Anonymize,
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("pruning workspace %s failed: %w", teamID, err)
+		}
+		pruned += n
+	}
+
+	if p.policy.DefaultDays > 0 {
+		n, err := p.repo.PruneEmotions(ctx, domain.PruneEmotionsRequest{
+			Before:         cutoff(now, p.policy.DefaultDays),
+			ExcludeTeamIDs: overridden,
+			Anonymize:      p.policy.Anonymize,
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("pruning failed: %w", err)
+		}
+		pruned += n
+	}
+
+	return pruned, nil
+}
+
+// Run prunes periodically until the context is cancelled.
+func (p *Pruner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := p.Prune(ctx, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "error pruning emotions", "error", err)
+		} else {
+			slog.InfoContext(ctx, "pruned emotions", "count", pruned)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
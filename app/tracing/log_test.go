@@ -0,0 +1,36 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/omegaatt36/cerberus/app/tracing"
+)
+
+func TestLogHandler(t *testing.T) {
+	s := require.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	logger.InfoContext(ctx, "traced")
+
+	var record map[string]any
+	s.NoError(json.Unmarshal(buf.Bytes(), &record))
+	s.Equal(span.SpanContext().TraceID().String(), record["trace_id"])
+	s.Equal(span.SpanContext().SpanID().String(), record["span_id"])
+
+	buf.Reset()
+	logger.Info("untraced")
+	s.NoError(json.Unmarshal(buf.Bytes(), &record))
+	s.NotContains(buf.String(), "trace_id")
+}
@@ -0,0 +1,95 @@
+// Package tracing sets up OpenTelemetry tracing exported via OTLP.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Config defines the tracing exporter from cli flags.
+type Config struct {
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+// CliFlags returns cli flag list.
+func (c *Config) CliFlags() []cli.Flag {
+	var flags []cli.Flag
+	flags = append(flags, &cli.StringFlag{
+		Name:        "tracing-endpoint",
+		Usage:       "OTLP/HTTP endpoint which receives the traces, e.g. localhost:4318, empty disables tracing",
+		EnvVars:     []string{"TRACING_ENDPOINT"},
+		Destination: &c.Endpoint,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "tracing-insecure",
+		Usage:       "export the traces without TLS",
+		EnvVars:     []string{"TRACING_INSECURE"},
+		Destination: &c.Insecure,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "tracing-service-name",
+		EnvVars:     []string{"TRACING_SERVICE_NAME"},
+		Value:       "cerberus",
+		Destination: &c.ServiceName,
+	})
+	flags = append(flags, &cli.Float64Flag{
+		Name:        "tracing-sample-ratio",
+		Usage:       "ratio of traces which are sampled, between 0 and 1",
+		EnvVars:     []string{"TRACING_SAMPLE_RATIO"},
+		Value:       1,
+		Destination: &c.SampleRatio,
+	})
+
+	return flags
+}
+
+// Enabled reports whether the traces are exported.
+func (c *Config) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// Setup installs the global tracer provider which exports to the endpoint. The returned function
+// flushes the remaining spans and must be called before the process exits.
+func (c *Config) Setup(ctx context.Context) (func(context.Context) error, error) {
+	if !c.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.Endpoint)}
+	if c.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(c.ServiceName)))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("create resource: %w", err), exporter.Shutdown(ctx))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
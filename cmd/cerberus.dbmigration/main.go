@@ -2,35 +2,73 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"github.com/omegaatt36/cerberus/app"
 	"github.com/omegaatt36/cerberus/persistence/database"
 	"github.com/omegaatt36/cerberus/persistence/migration"
 	"github.com/omegaatt36/cerberus/persistence/migration/cerberus"
+	"github.com/omegaatt36/cerberus/persistence/repository"
 )
 
 var config struct {
 	databaseConnectionOption database.ConnectOption
 	rollback                 bool
+
+	to     string
+	dryRun bool
 }
 
+// db is the database connection, opened in before and closed in after.
+var db *database.DB
+
 func before(_ *cli.Context) error {
-	return database.Initialize(config.databaseConnectionOption)
+	// Migrations only run against the primary.
+	opt := config.databaseConnectionOption
+	opt.ReplicaHosts = cli.StringSlice{}
+
+	var err error
+	db, err = database.New(opt)
+	return err
 }
 
 func after(_ *cli.Context) error {
-	return database.Finalize()
+	if db == nil {
+		return nil
+	}
+
+	return db.Close()
 }
 
-func action(ctx context.Context) {
-	db := database.GetDB().Debug()
-	mg := migration.NewMigrator(db, []any{}, cerberus.MigrationList)
+// newMigrator creates the migrator, which logs every statement unless it only plans a dry run.
+func newMigrator() *migration.Migrator {
+	conn := db.GetDB()
+	if !config.dryRun {
+		conn = conn.Debug()
+	}
+
+	return migration.NewMigrator(conn, []any{}, cerberus.MigrationList)
+}
+
+// migrate calls fn while holding the migration lock, so it does not race the replicas which upgrade at
+// startup, and refuses to touch a schema which is newer than the binary.
+func migrate(ctx context.Context, fn func(mg *migration.Migrator) error) error {
+	return newMigrator().WithLock(ctx, func(mg *migration.Migrator) error {
+		if err := mg.CheckUnknown(); err != nil {
+			return err
+		}
+
+		return fn(mg)
+	})
+}
 
+func action(ctx context.Context) {
 	if config.rollback {
-		if err := mg.Rollback(); err != nil {
+		if err := migrate(ctx, (*migration.Migrator).Rollback); err != nil {
 			slog.Error("rollback error", slog.String("error", err.Error()))
 			panic(err)
 		}
@@ -38,12 +76,106 @@ func action(ctx context.Context) {
 		return
 	}
 
-	if err := mg.Upgrade(); err != nil {
+	if err := migrate(ctx, (*migration.Migrator).Upgrade); err != nil {
 		slog.Error("upgrade error", slog.String("error", err.Error()))
 		panic(err)
 	}
 }
 
+func statusAction(_ context.Context) {
+	statuses, err := migration.NewMigrator(db.GetDB(), []any{}, cerberus.MigrationList).Status()
+	if err != nil {
+		slog.Error("status error", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%s\t%s\n", state, status.ID)
+	}
+}
+
+func upAction(ctx context.Context) {
+	if config.dryRun {
+		plans, err := newMigrator().DryRunMigrateTo(config.to)
+		if err != nil {
+			slog.Error("dry run error", slog.String("error", err.Error()))
+			panic(err)
+		}
+
+		printPlans(plans)
+		return
+	}
+
+	if err := migrate(ctx, func(mg *migration.Migrator) error {
+		if config.to == "" {
+			return mg.Upgrade()
+		}
+		return mg.MigrateTo(config.to)
+	}); err != nil {
+		slog.Error("upgrade error", slog.String("error", err.Error()))
+		panic(err)
+	}
+}
+
+func downAction(ctx context.Context) {
+	if config.dryRun {
+		plans, err := newMigrator().DryRunRollbackTo(config.to)
+		if err != nil {
+			slog.Error("dry run error", slog.String("error", err.Error()))
+			panic(err)
+		}
+
+		printPlans(plans)
+		return
+	}
+
+	if err := migrate(ctx, func(mg *migration.Migrator) error {
+		return mg.RollbackTo(config.to)
+	}); err != nil {
+		slog.Error("rollback error", slog.String("error", err.Error()))
+		panic(err)
+	}
+}
+
+func verifyAction(ctx context.Context) {
+	diff, err := migration.Verify(ctx, db.GetDB(), cerberus.MigrationList, func(db *gorm.DB) error {
+		return repository.NewGORMRepository(db).AutoMigrate()
+	})
+	if err != nil {
+		slog.Error("verify error", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	if len(diff) == 0 {
+		fmt.Println("the migrations match the models")
+		return
+	}
+
+	fmt.Println("the migrations diverged from the models (- models only, + migrations only):")
+	for _, line := range diff {
+		fmt.Println(line)
+	}
+	panic(fmt.Errorf("schema drift in %d definitions", len(diff)))
+}
+
+func printPlans(plans []migration.PlannedMigration) {
+	if len(plans) == 0 {
+		fmt.Println("-- nothing to run")
+		return
+	}
+
+	for _, plan := range plans {
+		fmt.Printf("-- %s\n", plan.ID)
+		for _, statement := range plan.SQL {
+			fmt.Printf("%s;\n", statement)
+		}
+	}
+}
+
 func main() {
 	cliFlags := []cli.Flag{
 		&cli.BoolFlag{
@@ -54,11 +186,56 @@ func main() {
 		}}
 	cliFlags = append(cliFlags, config.databaseConnectionOption.CliFlags()...)
 
+	dryRunFlag := &cli.BoolFlag{
+		Name:        "dry-run",
+		Usage:       "prints the SQL which would run instead of running it",
+		Destination: &config.dryRun,
+	}
+
 	server := app.App{
 		Action: action,
 		Before: before,
 		After:  after,
 		Flags:  cliFlags,
+		Commands: []*app.Command{
+			{
+				Name:   "status",
+				Usage:  "lists the applied and pending migrations",
+				Action: statusAction,
+			},
+			{
+				Name:   "verify",
+				Usage:  "compares the schema built by the migrations with the schema of the models",
+				Action: verifyAction,
+			},
+			{
+				Name:  "up",
+				Usage: "applies the pending migrations",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "to",
+						Usage:       "ID of the last migration to apply, defaults to the latest",
+						Destination: &config.to,
+					},
+					dryRunFlag,
+				},
+				Action: upAction,
+			},
+			{
+				Name:  "down",
+				Usage: "rollbacks the migrations applied after a migration",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "to",
+						Usage:       "ID of the migration to rollback to, which stays applied",
+						Required:    true,
+						Destination: &config.to,
+					},
+					dryRunFlag,
+				},
+				Action: downAction,
+			},
+		},
 	}
 
 	server.Run()
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/omegaatt36/cerberus/app"
+	"github.com/omegaatt36/cerberus/app/retention"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+var config struct {
+	databaseConnectionOption database.ConnectOption
+	retention                retention.Config
+	encryptionKeyFile        string
+}
+
+// db is the database connection, opened in before and closed in after.
+var db *database.DB
+
+// keyring encrypts personal content at rest, loaded in before, nil stores it as plain text.
+var keyring *encryption.Keyring
+
+func before(_ *cli.Context) error {
+	if config.encryptionKeyFile != "" {
+		var err error
+		keyring, err = encryption.LoadKeyringFile(config.encryptionKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	db, err = database.New(config.databaseConnectionOption)
+	return err
+}
+
+func after(_ *cli.Context) error {
+	if db == nil {
+		return nil
+	}
+
+	return db.Close()
+}
+
+func action(ctx context.Context) {
+	policy, err := config.retention.Policy()
+	if err != nil {
+		slog.Error("invalid retention policy", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	if !policy.Enabled() {
+		slog.Info("retention is disabled, nothing to prune")
+		return
+	}
+
+	repo := repository.NewGORMRepository(db.GetDB(), &repository.WithKeyringOption{Keyring: keyring})
+	pruned, err := retention.NewPruner(repo, policy).Prune(ctx, time.Now())
+	if err != nil {
+		slog.Error("prune error", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	slog.Info("pruned emotions", slog.Int64("count", pruned))
+}
+
+func main() {
+	var cliFlags []cli.Flag
+	cliFlags = append(cliFlags, config.databaseConnectionOption.CliFlags()...)
+	cliFlags = append(cliFlags, config.retention.CliFlags()...)
+	cliFlags = append(cliFlags, &cli.StringFlag{
+		Name:        "encryption-key-file",
+		Usage:       "JSON key file which enables the encryption of emotion descriptions and tasks at rest",
+		EnvVars:     []string{"ENCRYPTION_KEY_FILE"},
+		Destination: &config.encryptionKeyFile,
+	})
+
+	server := app.App{
+		Action: action,
+		Before: before,
+		After:  after,
+		Flags:  cliFlags,
+	}
+
+	server.Run()
+}
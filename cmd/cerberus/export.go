@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/omegaatt36/cerberus/app"
+	"github.com/omegaatt36/cerberus/app/export"
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+func exportCommand() *app.Command {
+	return &app.Command{
+		Name:  "export",
+		Usage: "exports all emotions of a user, e.g. to handle a data request",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "user",
+				Usage:       "Slack user ID",
+				Required:    true,
+				Destination: &config.export.userID,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "[csv|json]",
+				Value:       string(export.FormatCSV),
+				Destination: &config.export.format,
+			},
+			&cli.StringFlag{
+				Name:        "range",
+				Usage:       "all, <n>d or YYYY-MM-DD..YYYY-MM-DD",
+				Value:       "all",
+				Destination: &config.export.dateRange,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "output file path, defaults to cerberus-export-<user>-<date>.<format>",
+				Destination: &config.export.output,
+			},
+		},
+		Action: exportAction,
+	}
+}
+
+func exportAction(ctx context.Context) {
+	if err := exportEmotions(ctx); err != nil {
+		slog.Error("export error", slog.String("error", err.Error()))
+		panic(err)
+	}
+}
+
+func exportEmotions(ctx context.Context) error {
+	format, err := export.ParseFormat(config.export.format)
+	if err != nil {
+		return err
+	}
+
+	r, err := export.ParseRange(config.export.dateRange, time.Now())
+	if err != nil {
+		return err
+	}
+
+	output := config.export.output
+	if output == "" {
+		output = fmt.Sprintf("cerberus-export-%s-%s.%s", config.export.userID, time.Now().UTC().Format("20060102"), format)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	count, err := export.Write(ctx, f, format, newRepository(), domain.ListEmotionsRequest{
+		UserID: config.export.userID,
+		Since:  r.Since,
+		Until:  r.Until,
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// The file is only complete when it is closed without an error.
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s failed: %w", output, err)
+	}
+
+	slog.Info("exported emotions", slog.String("user_id", config.export.userID),
+		slog.Int("count", count), slog.String("output", output))
+	return nil
+}
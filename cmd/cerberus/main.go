@@ -2,64 +2,276 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	slogzap "github.com/samber/slog-zap/v2"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 
 	"github.com/omegaatt36/cerberus/app"
+	"github.com/omegaatt36/cerberus/app/ai"
 	"github.com/omegaatt36/cerberus/app/cerberus"
+	"github.com/omegaatt36/cerberus/app/health"
+	"github.com/omegaatt36/cerberus/app/metrics"
+	"github.com/omegaatt36/cerberus/app/retention"
+	"github.com/omegaatt36/cerberus/app/tracing"
+	"github.com/omegaatt36/cerberus/domain"
 	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+	"github.com/omegaatt36/cerberus/persistence/migration"
+	cerberusmigration "github.com/omegaatt36/cerberus/persistence/migration/cerberus"
 	"github.com/omegaatt36/cerberus/persistence/repository"
+	"github.com/omegaatt36/cerberus/pkg/cache"
 	"github.com/omegaatt36/cerberus/pkg/gemini"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+	"github.com/omegaatt36/cerberus/pkg/redact"
 )
 
 var config struct {
 	databaseConnectionOption database.ConnectOption
+	retention                retention.Config
+	tracing                  tracing.Config
 	logLevel                 string
+	encryptionKeyFile        string
+	httpAddr                 string
+	autoMigrate              bool
 
 	slackBotToken string
 	slackAppToken string
 
 	geminiAPIKey string
 	geminiModel  string
+
+	safetyKeywords          cli.StringSlice
+	safetySupportMessage    string
+	safetyNotifyUserGroupID string
+
+	redactTerms cli.StringSlice
+
+	aiBreakerThreshold int
+	aiBreakerCooldown  time.Duration
+	aiPricing          cli.StringSlice
+	aiDailyTokenBudget int
+	aiCacheStore       string
+	aiCacheTTL         time.Duration
+	aiCacheSize        int
+
+	adminUserIDs cli.StringSlice
+
+	rateLimitInterval time.Duration
+	rateLimitBurst    int
+	rateLimitStore    string
+
+	buddyCheckInterval time.Duration
+	buddyCooldown      time.Duration
+
+	export struct {
+		userID    string
+		format    string
+		dateRange string
+		output    string
+	}
+
+	reencryptBatchSize int
 }
 
-var (
-	geminiService *gemini.Service
-	db            *sql.DB
-)
+// db is the database connection, opened in before and closed in after.
+var db *database.DB
 
-func before(ctx *cli.Context) error {
+// keyring encrypts personal content at rest, loaded in before, nil stores it as plain text.
+var keyring *encryption.Keyring
+
+// newRepository creates the repository on the database connection with the keyring.
+func newRepository() *repository.GORMRepository {
+	return repository.NewGORMRepository(db.GetDB(), &repository.WithKeyringOption{Keyring: keyring})
+}
+
+func before(_ *cli.Context) error {
 	if err := initSLog(config.logLevel); err != nil {
 		return err
 	}
 
-	service, err := gemini.NewService(ctx.Context, config.geminiAPIKey, config.geminiModel)
-	if err != nil {
-		return err
+	if config.encryptionKeyFile != "" {
+		var err error
+		keyring, err = encryption.LoadKeyringFile(config.encryptionKeyFile)
+		if err != nil {
+			return err
+		}
 	}
 
-	geminiService = service
-
-	return database.Initialize(config.databaseConnectionOption)
+	var err error
+	db, err = database.New(config.databaseConnectionOption)
+	return err
 }
 
 func after(_ *cli.Context) error {
-	return errors.Join(db.Close(), geminiService.Close(), database.Finalize())
+	if db == nil {
+		return nil
+	}
+
+	return db.Close()
 }
 
+// requiredBotFlags are required to run the bot, but not by the sub commands.
+var requiredBotFlags = []string{"slack-bot-token", "slack-app-token", "gemini-api-key"}
+
 func action(ctx context.Context) {
+	for _, value := range []string{config.slackBotToken, config.slackAppToken, config.geminiAPIKey} {
+		if value == "" {
+			err := fmt.Errorf("flags %v are required to run the bot", requiredBotFlags)
+			slog.Error("invalid config", slog.String("error", err.Error()))
+			panic(err)
+		}
+	}
+
+	if config.autoMigrate {
+		mg := migration.NewMigrator(db.GetDB(), []any{}, cerberusmigration.MigrationList)
+		if err := mg.AutoUpgrade(ctx); err != nil {
+			slog.Error("auto migrate error", slog.String("error", err.Error()))
+			panic(err)
+		}
+	}
+
+	shutdownTracing, err := config.tracing.Setup(ctx)
+	if err != nil {
+		slog.Error("init tracing error", slog.String("error", err.Error()))
+		panic(err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("shutdown tracing error", slog.String("error", err.Error()))
+		}
+	}()
+
+	for _, plugin := range []gorm.Plugin{
+		metrics.GORMPlugin{},
+		gormtracing.NewPlugin(gormtracing.WithoutQueryVariables(), gormtracing.WithoutMetrics()),
+	} {
+		if err := db.GetDB().Use(plugin); err != nil {
+			slog.Error("init database plugin error", slog.String("plugin", plugin.Name()), slog.String("error", err.Error()))
+			panic(err)
+		}
+	}
+
+	pricing, err := ai.ParsePricing(config.aiPricing.Value())
+	if err != nil {
+		slog.Error("invalid ai pricing", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	repo := newRepository()
+	geminiService, err := gemini.NewService(ctx, config.geminiAPIKey, config.geminiModel,
+		&gemini.WithUsageHookOption{Hook: ai.NewUsageRecorder(repo).RecordGeminiUsage},
+	)
+	if err != nil {
+		slog.Error("init gemini service error", slog.String("error", err.Error()))
+		panic(err)
+	}
+	defer geminiService.Close()
+
+	var aiService domain.AIService = geminiService
+	aiService = ai.NewMetricsService(aiService, "gemini")
+	breaker := ai.NewCircuitBreakerService(aiService, config.aiBreakerThreshold, config.aiBreakerCooldown)
+	aiService = ai.NewTracingService(breaker, "gemini", config.geminiModel)
+	if config.aiDailyTokenBudget > 0 {
+		aiService = ai.NewBudgetService(aiService, repo, config.aiDailyTokenBudget)
+	}
+	namespace := config.geminiModel + "/" + gemini.PromptVersionEmotionScore
+	switch config.aiCacheStore {
+	case "none":
+	case "memory":
+		aiService = ai.NewCachingService(aiService, cache.NewLRU(config.aiCacheSize), namespace, config.aiCacheTTL)
+	case "database":
+		go prunePeriodically(ctx, "cache entries", time.Hour, repo.PruneCacheEntries)
+		aiService = ai.NewCachingService(aiService, repo, namespace, config.aiCacheTTL)
+	default:
+		err := fmt.Errorf("unknown ai cache store %q", config.aiCacheStore)
+		slog.Error("invalid config", slog.String("error", err.Error()))
+		panic(err)
+	}
+	aiService = ai.NewRedactingService(aiService, redact.NewRedactor(config.redactTerms.Value()))
+
+	var limiter *ratelimit.Limiter
+	if config.rateLimitBurst > 0 {
+		limit := ratelimit.Every(config.rateLimitInterval, config.rateLimitBurst)
+		switch config.rateLimitStore {
+		case "memory":
+			store := ratelimit.NewMemoryStore()
+			go store.RunCleanup(ctx, limit, time.Hour)
+			limiter = ratelimit.NewLimiter(store, limit)
+		case "database":
+			go prunePeriodically(ctx, "rate limit buckets", time.Hour, func(ctx context.Context, now time.Time) (int64, error) {
+				return repo.PruneRateLimitBuckets(ctx, limit, now)
+			})
+			limiter = ratelimit.NewLimiter(repo, limit)
+		default:
+			err := fmt.Errorf("unknown rate limit store %q", config.rateLimitStore)
+			slog.Error("invalid config", slog.String("error", err.Error()))
+			panic(err)
+		}
+	}
+
 	bot := cerberus.NewBot(config.slackBotToken, config.slackAppToken,
-		&cerberus.WithAIServiceOption{AIService: geminiService},
-		&cerberus.WithEmotionRepositoryOption{EmotionRepository: repository.NewGORMRepository(database.GetDB())},
+		&cerberus.WithAIServiceOption{AIService: aiService},
+		&cerberus.WithTransactorOption{Transactor: repo},
+		&cerberus.WithEmotionRepositoryOption{EmotionRepository: repo},
+		&cerberus.WithAuditLogRepositoryOption{AuditLogRepository: repo},
+		&cerberus.WithBuddyRepositoryOption{BuddyRepository: repo},
+		&cerberus.WithUserDataRepositoryOption{UserDataRepository: repo},
+		&cerberus.WithBuddyAlertOption{
+			CheckInterval: config.buddyCheckInterval,
+			Cooldown:      config.buddyCooldown,
+		},
+		&cerberus.WithAdminOption{UserIDs: config.adminUserIDs.Value()},
+		&cerberus.WithAIUsageOption{AIUsageRepository: repo, Pricing: pricing},
+		&cerberus.WithRateLimiterOption{Limiter: limiter},
+		&cerberus.WithSafetyOption{
+			Keywords:          config.safetyKeywords.Value(),
+			SupportMessage:    config.safetySupportMessage,
+			NotifyUserGroupID: config.safetyNotifyUserGroupID,
+		},
 	)
 
+	policy, err := config.retention.Policy()
+	if err != nil {
+		slog.Error("invalid retention policy", slog.String("error", err.Error()))
+		panic(err)
+	}
+	if policy.Enabled() {
+		go retention.NewPruner(repo, policy).Run(ctx, config.retention.Interval)
+	}
+
+	if config.httpAddr != "" {
+		checker := health.NewChecker(2 * time.Second)
+		checker.Add("slack", func(context.Context) error {
+			if !bot.Connected() {
+				return errors.New("not connected")
+			}
+			return nil
+		})
+		checker.Add("database", db.Ping)
+		checker.Add("ai", func(context.Context) error {
+			if state := breaker.State(); state == ai.CircuitOpen {
+				return fmt.Errorf("circuit %s", state)
+			}
+			return nil
+		})
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		mux.Handle("GET /healthz", health.LivenessHandler())
+		mux.Handle("GET /readyz", checker.ReadinessHandler())
+		go serveHTTP(ctx, config.httpAddr, mux)
+	}
+
 	bot.Run(ctx)
 }
 
@@ -77,7 +289,8 @@ func initSLog(logLevel string) error {
 
 	zapLogger := zap.New(core, zap.AddCaller())
 
-	slog.SetDefault(slog.New(slogzap.Option{Level: slog.LevelDebug, Logger: zapLogger}.NewZapHandler()))
+	slog.SetDefault(slog.New(tracing.NewLogHandler(
+		slogzap.Option{Level: slog.LevelDebug, Logger: zapLogger}.NewZapHandler())))
 
 	return nil
 }
@@ -88,21 +301,18 @@ func main() {
 			Name:        "slack-bot-token",
 			EnvVars:     []string{"SLACK_BOT_TOKEN"},
 			Value:       "",
-			Required:    true,
 			Destination: &config.slackBotToken,
 		},
 		&cli.StringFlag{
 			Name:        "slack-app-token",
 			EnvVars:     []string{"SLACK_APP_TOKEN"},
 			Value:       "",
-			Required:    true,
 			Destination: &config.slackAppToken,
 		},
 		&cli.StringFlag{
 			Name:        "gemini-api-key",
 			EnvVars:     []string{"GEMINI_API_KEY"},
 			Value:       "",
-			Required:    true,
 			Destination: &config.geminiAPIKey,
 		},
 		&cli.StringFlag{
@@ -112,14 +322,151 @@ func main() {
 			Required:    false,
 			Destination: &config.geminiModel,
 		},
+		&cli.StringFlag{
+			Name:        "encryption-key-file",
+			Usage:       "JSON key file which enables the encryption of emotion descriptions and tasks at rest",
+			EnvVars:     []string{"ENCRYPTION_KEY_FILE"},
+			Destination: &config.encryptionKeyFile,
+		},
+		&cli.StringFlag{
+			Name:        "http-addr",
+			Usage:       "address of the HTTP listener which exposes /metrics, /healthz and /readyz, e.g. :9090, empty disables it",
+			EnvVars:     []string{"HTTP_ADDR"},
+			Destination: &config.httpAddr,
+		},
+		&cli.BoolFlag{
+			Name:        "auto-migrate",
+			Usage:       "upgrades the database schema on startup, refusing to start if it is newer than the binary",
+			EnvVars:     []string{"AUTO_MIGRATE"},
+			Destination: &config.autoMigrate,
+		},
+		&cli.StringSliceFlag{
+			Name:        "safety-keywords",
+			Usage:       "keywords which trigger the safety escalation, empty uses the built-in list",
+			EnvVars:     []string{"SAFETY_KEYWORDS"},
+			Destination: &config.safetyKeywords,
+		},
+		&cli.StringFlag{
+			Name:        "safety-support-message",
+			Usage:       "reply with support resources when the safety escalation is triggered, empty uses the built-in message",
+			EnvVars:     []string{"SAFETY_SUPPORT_MESSAGE"},
+			Destination: &config.safetySupportMessage,
+		},
+		&cli.StringFlag{
+			Name:        "safety-notify-user-group",
+			Usage:       "Slack user group ID whose members are notified when the safety escalation is triggered",
+			EnvVars:     []string{"SAFETY_NOTIFY_USER_GROUP"},
+			Destination: &config.safetyNotifyUserGroupID,
+		},
+		&cli.StringSliceFlag{
+			Name:        "redact-terms",
+			Usage:       "custom terms, e.g. project or customer names, which are redacted before text is sent to the AI service",
+			EnvVars:     []string{"REDACT_TERMS"},
+			Destination: &config.redactTerms,
+		},
+		&cli.IntFlag{
+			Name:        "ai-breaker-threshold",
+			Usage:       "consecutive AI service failures which open the circuit",
+			EnvVars:     []string{"AI_BREAKER_THRESHOLD"},
+			Value:       5,
+			Destination: &config.aiBreakerThreshold,
+		},
+		&cli.DurationFlag{
+			Name:        "ai-breaker-cooldown",
+			Usage:       "how long the open circuit rejects AI service calls before probing again",
+			EnvVars:     []string{"AI_BREAKER_COOLDOWN"},
+			Value:       30 * time.Second,
+			Destination: &config.aiBreakerCooldown,
+		},
+		&cli.StringSliceFlag{
+			Name:        "ai-pricing",
+			Usage:       "USD price per million prompt and completion tokens of a model, e.g. gemini-1.5-flash=0.075:0.3",
+			EnvVars:     []string{"AI_PRICING"},
+			Destination: &config.aiPricing,
+		},
+		&cli.IntFlag{
+			Name:        "ai-daily-token-budget",
+			Usage:       "tokens each user can use per UTC day, 0 is unlimited",
+			EnvVars:     []string{"AI_DAILY_TOKEN_BUDGET"},
+			Destination: &config.aiDailyTokenBudget,
+		},
+		&cli.StringFlag{
+			Name:        "ai-cache-store",
+			Usage:       "[none|memory|database], where the emotion scores of identical inputs are cached",
+			EnvVars:     []string{"AI_CACHE_STORE"},
+			Value:       "memory",
+			Destination: &config.aiCacheStore,
+		},
+		&cli.DurationFlag{
+			Name:        "ai-cache-ttl",
+			Usage:       "how long a cached emotion score is reused",
+			EnvVars:     []string{"AI_CACHE_TTL"},
+			Value:       24 * time.Hour,
+			Destination: &config.aiCacheTTL,
+		},
+		&cli.IntFlag{
+			Name:        "ai-cache-size",
+			Usage:       "emotion scores kept by the memory cache",
+			EnvVars:     []string{"AI_CACHE_SIZE"},
+			Value:       1000,
+			Destination: &config.aiCacheSize,
+		},
+		&cli.StringSliceFlag{
+			Name:        "admin-user-ids",
+			Usage:       "Slack user IDs which can use /emoji admin",
+			EnvVars:     []string{"ADMIN_USER_IDS"},
+			Destination: &config.adminUserIDs,
+		},
+		&cli.IntFlag{
+			Name:        "rate-limit-burst",
+			Usage:       "slash commands each user can run at once, 0 disables the rate limit",
+			EnvVars:     []string{"RATE_LIMIT_BURST"},
+			Value:       5,
+			Destination: &config.rateLimitBurst,
+		},
+		&cli.DurationFlag{
+			Name:        "rate-limit-interval",
+			Usage:       "how often each user gets another slash command once the burst is used up",
+			EnvVars:     []string{"RATE_LIMIT_INTERVAL"},
+			Value:       10 * time.Second,
+			Destination: &config.rateLimitInterval,
+		},
+		&cli.StringFlag{
+			Name:        "rate-limit-store",
+			Usage:       "[memory|database], database shares the limit between replicas",
+			EnvVars:     []string{"RATE_LIMIT_STORE"},
+			Value:       "memory",
+			Destination: &config.rateLimitStore,
+		},
+		&cli.DurationFlag{
+			Name:        "buddy-check-interval",
+			Usage:       "how often the low mood of buddy users is evaluated",
+			EnvVars:     []string{"BUDDY_CHECK_INTERVAL"},
+			Value:       time.Hour,
+			Destination: &config.buddyCheckInterval,
+		},
+		&cli.DurationFlag{
+			Name:        "buddy-cooldown",
+			Usage:       "minimum time between two notifications to the same buddy",
+			EnvVars:     []string{"BUDDY_COOLDOWN"},
+			Value:       72 * time.Hour,
+			Destination: &config.buddyCooldown,
+		},
 	}
 	cliFlags = append(cliFlags, config.databaseConnectionOption.CliFlags()...)
+	cliFlags = append(cliFlags, config.retention.CliFlags()...)
+	cliFlags = append(cliFlags, config.tracing.CliFlags()...)
 
 	server := &app.App{
 		Action: action,
 		Before: before,
 		After:  after,
 		Flags:  cliFlags,
+		Commands: []*app.Command{
+			exportCommand(),
+			reencryptCommand(),
+			rebuildStatsCommand(),
+		},
 	}
 
 	server.Run()
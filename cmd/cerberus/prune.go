@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// prunePeriodically calls prune periodically until the context is cancelled, what names the pruned rows
+// in the logs.
+func prunePeriodically(ctx context.Context, what string, interval time.Duration,
+	prune func(ctx context.Context, now time.Time) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := prune(ctx, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "error pruning "+what, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/omegaatt36/cerberus/app"
+)
+
+func rebuildStatsCommand() *app.Command {
+	return &app.Command{
+		Name:   "rebuild-stats",
+		Usage:  "recomputes the daily statistics from the emotions",
+		Action: rebuildStatsAction,
+	}
+}
+
+func rebuildStatsAction(ctx context.Context) {
+	count, err := newRepository().RebuildDailyStats(ctx)
+	if err != nil {
+		slog.Error("rebuild-stats error", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	slog.Info("rebuilt daily stats", slog.Int("count", count))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/omegaatt36/cerberus/app"
+)
+
+func reencryptCommand() *app.Command {
+	return &app.Command{
+		Name:  "reencrypt",
+		Usage: "encrypts existing emotions under the active key of --encryption-key-file, e.g. after a key rotation",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "batch-size",
+				Value:       100,
+				Destination: &config.reencryptBatchSize,
+			},
+		},
+		Action: reencryptAction,
+	}
+}
+
+func reencryptAction(ctx context.Context) {
+	count, err := newRepository().ReencryptEmotions(ctx, config.reencryptBatchSize)
+	if err != nil {
+		slog.Error("reencrypt error", slog.String("error", err.Error()), slog.Int("reencrypted", count))
+		panic(err)
+	}
+
+	slog.Info("reencrypted emotions", slog.Int("count", count))
+}
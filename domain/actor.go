@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// Actor represents the Slack user on whose behalf a request is handled.
+type Actor struct {
+	TeamID string
+	UserID string
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a copy of the context which carries the actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor of the context, if any.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorContextKey{}).(Actor)
+	return actor, ok
+}
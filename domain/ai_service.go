@@ -7,4 +7,5 @@ type AIService interface {
 	GetEmotionScore(ctx context.Context, input string) (int, error)
 	GenerateTaskSuggestion(ctx context.Context, emoji string, description string, score int) (string, error)
 	GenerateDailySummary(ctx context.Context, averageScore float64) (string, error)
+	DetectCrisis(ctx context.Context, input string) (bool, error)
 }
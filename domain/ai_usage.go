@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CreateAIUsageRequest represents the tokens used by an AI service call
+type CreateAIUsageRequest struct {
+	TeamID           string
+	UserID           string
+	Provider         string
+	Model            string
+	Method           string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// SumAIUsageRequest represents the AI usage to sum up, empty fields are not filtered
+type SumAIUsageRequest struct {
+	TeamID string
+	UserID string
+	Since  *time.Time
+	Until  *time.Time
+}
+
+// AIUsageSummary represents the tokens used by a user with a model
+type AIUsageSummary struct {
+	UserID           string
+	Model            string
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// AIUsageRepository defines the interface for AI usage data persistence
+type AIUsageRepository interface {
+	CreateAIUsage(ctx context.Context, req CreateAIUsageRequest) error
+	// SumAIUsage sums the usage up by user and model.
+	SumAIUsage(ctx context.Context, req SumAIUsageRequest) ([]AIUsageSummary, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction represents the kind of an audited action.
+type AuditAction string
+
+const (
+	// AuditActionSafetyEscalation is recorded when a message triggers the safety escalation.
+	AuditActionSafetyEscalation AuditAction = "safety_escalation"
+	// AuditActionUserPurged is recorded when a user deletes all of their data. It never contains the user ID.
+	AuditActionUserPurged AuditAction = "user_purged"
+)
+
+// AuditLog represents an audit trail entry
+type AuditLog struct {
+	ID        int
+	CreatedAt time.Time
+	Action    AuditAction
+	UserID    string
+	Detail    string
+}
+
+// CreateAuditLogRequest represents the data required to create a new AuditLog
+type CreateAuditLogRequest struct {
+	Action AuditAction
+	UserID string
+	Detail string
+}
+
+// AuditLogRepository defines the interface for AuditLog data persistence
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, req CreateAuditLogRequest) (int, error)
+}
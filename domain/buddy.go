@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BuddyStatus represents the consent state of a buddy.
+type BuddyStatus string
+
+const (
+	// BuddyStatusPending means the buddy has not answered the invitation yet.
+	BuddyStatusPending BuddyStatus = "pending"
+	// BuddyStatusActive means the buddy accepted the invitation.
+	BuddyStatusActive BuddyStatus = "active"
+	// BuddyStatusDeclined means the buddy declined the invitation.
+	BuddyStatusDeclined BuddyStatus = "declined"
+)
+
+// Buddy represents a user who gets notified when another user's mood stays low
+type Buddy struct {
+	ID             int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         string
+	BuddyUserID    string
+	Status         BuddyStatus
+	Threshold      int
+	Days           int
+	ConfirmedAt    *time.Time
+	LastNotifiedAt *time.Time
+}
+
+// CreateBuddyRequest represents the data required to create a new Buddy
+type CreateBuddyRequest struct {
+	UserID      string
+	BuddyUserID string
+	Threshold   int
+	Days        int
+}
+
+// UpdateBuddyRequest represents the data that can be updated for an existing Buddy
+type UpdateBuddyRequest struct {
+	Status         *BuddyStatus
+	ConfirmedAt    *time.Time
+	LastNotifiedAt *time.Time
+}
+
+// ListBuddiesRequest represents the filter for listing buddies
+type ListBuddiesRequest struct {
+	Status *BuddyStatus
+}
+
+// BuddyRepository defines the interface for Buddy data persistence
+type BuddyRepository interface {
+	CreateBuddy(ctx context.Context, req CreateBuddyRequest) (int, error)
+	GetBuddy(ctx context.Context, id int) (*Buddy, error)
+	UpdateBuddy(ctx context.Context, id int, req UpdateBuddyRequest) error
+	DeleteBuddyByUserID(ctx context.Context, userID string) error
+	ListBuddies(ctx context.Context, req ListBuddiesRequest) ([]Buddy, error)
+}
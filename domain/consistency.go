@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+type readYourWritesContextKey struct{}
+
+// WithReadYourWrites returns a copy of the context whose reads must see the writes which were just made,
+// e.g. to undo the check-in just created, so the repositories read them from the primary database.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesContextKey{}, true)
+}
+
+// ReadYourWritesFromContext reports whether the reads of the context must see the preceding writes.
+func ReadYourWritesFromContext(ctx context.Context) bool {
+	readYourWrites, _ := ctx.Value(readYourWritesContextKey{}).(bool)
+	return readYourWrites
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DailyStat represents the aggregated emotions on a day in the time zone of the user.
+type DailyStat struct {
+	// Date is the day in YYYY-MM-DD format.
+	Date  string
+	Count int
+	// ScoredCount is the number of scored emotions, which the scores are computed from. The scores are 0
+	// without any.
+	ScoredCount    int
+	AvgScore       float64
+	MinScore       int
+	MaxScore       int
+	TasksCompleted int
+}
+
+// ListUserDailyStatsRequest represents the daily statistics of a user to list, across channels.
+type ListUserDailyStatsRequest struct {
+	TeamID string
+	UserID string
+	// From and To are inclusive, only their dates are used.
+	From time.Time
+	To   time.Time
+}
+
+// ListChannelDailyStatsRequest represents the daily statistics of a channel to list, across users.
+type ListChannelDailyStatsRequest struct {
+	TeamID    string
+	ChannelID string
+	// From and To are inclusive, only their dates are used.
+	From time.Time
+	To   time.Time
+}
+
+// DailyStatRepository defines the interface for querying the daily statistics of emotions
+type DailyStatRepository interface {
+	ListUserDailyStats(ctx context.Context, req ListUserDailyStatsRequest) ([]DailyStat, error)
+	ListChannelDailyStats(ctx context.Context, req ListChannelDailyStatsRequest) ([]DailyStat, error)
+	// RebuildDailyStats recomputes the daily statistics from the emotions, and returns the number of
+	// recomputed statistics. Statistics whose emotions were already pruned are kept as they are.
+	RebuildDailyStats(ctx context.Context) (int, error)
+}
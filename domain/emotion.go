@@ -7,21 +7,31 @@ import (
 
 // Emotion represents an emotional state with associated metadata
 type Emotion struct {
-	ID              int
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	UserID          string
-	Emoji           string
-	Description     string
-	Score           int
+	ID          int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	TeamID      string
+	ChannelID   string
+	UserID      string
+	TimeZone    string
+	Emoji       string
+	Description string
+	// Score is nil until the emotion is scored.
+	Score           *int
 	MessagedAt      *time.Time
 	Task            string
 	TaskCompletedAt *time.Time
+	// Version is incremented by every update.
+	Version int
 }
 
 // CreateEmotionRequest represents the data required to create a new Emotion
 type CreateEmotionRequest struct {
-	UserID      string
+	TeamID    string
+	ChannelID string
+	UserID    string
+	// TimeZone is the IANA time zone of the user, which decides the day of the emotion in the daily statistics.
+	TimeZone    string
 	Emoji       string
 	Description string
 }
@@ -34,10 +44,27 @@ type UpdateEmotionRequest struct {
 	MessagedAt      *time.Time
 	Task            *string
 	TaskCompletedAt *time.Time
+	// Version is the version the emotion is expected to have, usually the one it was read with.
+	// The update fails with ErrConflict if it has changed since; nil expects the current version.
+	Version *int
+}
+
+// ListEmotionsRequest represents the filter for listing emotions
+type ListEmotionsRequest struct {
+	UserID string
+	Since  *time.Time
+	Until  *time.Time
+	// After lists the emotions which come after the emotion, usually the last one of the previous page.
+	After *Emotion
+	// Limit is the maximum number of emotions to list, zero means no limit.
+	Limit int
 }
 
 // EmotionRepository defines the interface for Emotion data persistence
 type EmotionRepository interface {
 	CreateEmotion(ctx context.Context, req CreateEmotionRequest) (int, error)
 	UpdateEmotion(ctx context.Context, id int, req UpdateEmotionRequest) error
+	GetLatestEmotion(ctx context.Context, userID string) (*Emotion, error)
+	DeleteEmotion(ctx context.Context, id int, userID string) error
+	ListEmotions(ctx context.Context, req ListEmotionsRequest) ([]Emotion, error)
 }
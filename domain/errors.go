@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrPermissionDenied is returned when the caller does not own the requested record.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrConflict is returned when the record was changed by someone else since it was read.
+	ErrConflict = errors.New("conflict")
+	// ErrAIBudgetExceeded is returned when the user used up the daily AI token budget.
+	ErrAIBudgetExceeded = errors.New("daily AI token budget exceeded")
+)
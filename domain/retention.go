@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PruneEmotionsRequest represents the emotions to prune
+type PruneEmotionsRequest struct {
+	// Before prunes emotions created on the days before the UTC date of the time, where each emotion's
+	// day is in its own time zone like in the daily statistics, so a day is always pruned at once.
+	Before time.Time
+	// TeamIDs limits the pruning to the workspaces, empty means all workspaces.
+	TeamIDs []string
+	// ExcludeTeamIDs skips the workspaces.
+	ExcludeTeamIDs []string
+	// Anonymize keeps the emotions without the user and personal content instead of deleting them.
+	Anonymize bool
+}
+
+// RetentionRepository defines the interface for pruning data past its retention
+type RetentionRepository interface {
+	// PruneEmotions rolls the emotions up into the daily statistics, then deletes or anonymizes them.
+	// It returns the number of pruned emotions.
+	PruneEmotions(ctx context.Context, req PruneEmotionsRequest) (int64, error)
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// Transactor runs units of work which span several repository calls atomically.
+type Transactor interface {
+	// WithinTx calls fn in a transaction, which is committed if fn returns nil and rolled back otherwise.
+	// The repository calls made with the context passed to fn join the transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// PurgeUserResult represents the number of deleted rows per table of a purge
+type PurgeUserResult struct {
+	DeletedRows map[string]int64
+}
+
+// UserDataRepository defines the interface for operations across all data of a user
+type UserDataRepository interface {
+	// PurgeUser hard deletes every row belonging to the user in a single transaction,
+	// and records a non-identifying audit log.
+	PurgeUser(ctx context.Context, userID string) (PurgeUserResult, error)
+}
@@ -1,129 +1,121 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// database defines database instance.
-type database struct {
+// DB is a database connection, created by New and released by Close.
+type DB struct {
 	Opt ConnectOption
 	db  *gorm.DB
 }
 
-var mutex sync.Mutex
-var db *database
-
-// Initialize inits singleton.
-func Initialize(opt ConnectOption) error {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if db != nil {
-		slog.Warn("database already initialized")
-		return nil
+// New opens a new database connection.
+func New(opt ConnectOption) (*DB, error) {
+	if err := opt.readSecretFiles(); err != nil {
+		return nil, err
 	}
 
-	if err := initializeDB(opt); err != nil {
-		return fmt.Errorf("database init error: %w", err)
+	d := &DB{Opt: opt}
+	if err := d.open(); err != nil {
+		return nil, err
 	}
 
-	return nil
-}
-
-// Finalize finalizes singleton.
-func Finalize() error {
-	mutex.Lock()
-	defer mutex.Unlock()
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return nil, err
+	}
 
-	if err := db.Close(); err != nil {
-		return fmt.Errorf("close database error: %w", err)
+	// Zero values keep the defaults of database/sql.
+	if opt.Dialect == "postgres" {
+		if opt.ConnMaxLifetime > 0 {
+			sqlDB.SetConnMaxLifetime(opt.ConnMaxLifetime)
+		}
+		if opt.ConnMaxIdleTime > 0 {
+			sqlDB.SetConnMaxIdleTime(opt.ConnMaxIdleTime)
+		}
+		if opt.MaxIdleConns > 0 {
+			sqlDB.SetMaxIdleConns(opt.MaxIdleConns)
+		}
+		if opt.MaxOpenConns > 0 {
+			sqlDB.SetMaxOpenConns(opt.MaxOpenConns)
+		}
 	}
 
-	db = nil
+	if err := d.useReplicas(); err != nil {
+		return nil, errors.Join(err, sqlDB.Close())
+	}
 
-	return nil
+	return d, nil
 }
 
-func initializeDB(opt ConnectOption) error {
-	db = &database{}
-	db.Opt = opt
-	if err := db.Open(); err != nil {
-		return err
+// GetDB gets a new session of the gorm db.
+func (d *DB) GetDB() *gorm.DB {
+	if d == nil || d.db == nil {
+		panic("database is not initialized.")
 	}
 
-	sqlDB, err := db.db.DB()
-	if err != nil {
-		return err
-	}
+	return d.db.Session(&gorm.Session{})
+}
 
-	if opt.Dialect == "postgres" {
-		sqlDB.SetConnMaxLifetime(10 * time.Minute)
-		sqlDB.SetMaxIdleConns(20)
-		sqlDB.SetMaxOpenConns(20)
+// Ping verifies the connection to the database is alive.
+func (d *DB) Ping(ctx context.Context) error {
+	sqlDB, err := d.GetDB().DB()
+	if err != nil {
+		return fmt.Errorf("get db connection error: %w", err)
 	}
 
-	return nil
-}
-
-// GetDB gets db from singleton.
-func GetDB() *gorm.DB {
-	return db.getDB()
+	return sqlDB.PingContext(ctx)
 }
 
 // AutoMigrate migrates table.
-func AutoMigrate(models []any) {
+func (d *DB) AutoMigrate(models []any) {
 	for _, m := range models {
-		err := db.getDB().AutoMigrate(m)
+		err := d.GetDB().AutoMigrate(m)
 		if err != nil {
 			slog.Error("AutoMigrate error", slog.String("error", err.Error()))
 		}
 	}
 }
 
-// getDB get gorm db instance.
-func (db *database) getDB() *gorm.DB {
-	if db.db == nil {
-		panic("database is not initialized.")
-	}
-
-	return db.db.Session(&gorm.Session{})
-}
-
-// Open opens database connection.
-func (db *database) Open() error {
-	if db.db != nil {
+// open opens database connection.
+func (d *DB) open() error {
+	if d.db != nil {
 		return nil
 	}
 
-	dialector := db.Opt.Dialector()
+	dialector := d.Opt.Dialector()
 	if dialector == nil {
-		return fmt.Errorf("gorm driver open dialector fail, connect str: (%v)", db.Opt.ConnStr())
+		return fmt.Errorf("gorm driver open dialector fail, dialect: (%v)", d.Opt.Dialect)
 	}
 
-	if db.Opt.Silence {
-		db.Opt.Config.Logger = logger.Discard
-	} else if db.Opt.Logger != nil {
-		db.Opt.Config.Logger = db.Opt.Logger
+	if d.Opt.Silence {
+		d.Opt.Config.Logger = logger.Discard
+	} else if d.Opt.Logger != nil {
+		d.Opt.Config.Logger = d.Opt.Logger
+	} else if d.Opt.Config.Logger == nil {
+		d.Opt.Config.Logger = NewSlogLogger(time.Duration(d.Opt.SlowThresholdMilliseconds) * time.Millisecond)
 	}
-	conn, err := gorm.Open(dialector, &db.Opt.Config)
+	conn, err := gorm.Open(dialector, &d.Opt.Config)
 	if err != nil {
-		return fmt.Errorf("sql.Open(%v): %w", db.Opt.ConnStr(), err)
+		return fmt.Errorf("sql.Open(%v): %w", d.Opt.Dialect, err)
 	}
 
-	db.db = conn
+	d.db = conn
 
 	return nil
 }
 
 // Close closes db connection.
-func (db *database) Close() error {
-	realConn, err := db.db.DB()
+func (d *DB) Close() error {
+	realConn, err := d.db.DB()
 	if err != nil {
 		return fmt.Errorf("get db connection when close db error: %w", err)
 	}
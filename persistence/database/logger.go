@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	_ logger.Interface  = (*SlogLogger)(nil)
+	_ gorm.ParamsFilter = (*SlogLogger)(nil)
+)
+
+// redacted replaces the parameters of logged statements which may contain personal content.
+const redacted = "<redacted>"
+
+type logAttrsContextKey struct{}
+
+// WithLogAttrs returns a context whose statements are logged with attrs, e.g. the actor of the request.
+func WithLogAttrs(ctx context.Context, attrs ...any) context.Context {
+	return context.WithValue(ctx, logAttrsContextKey{}, append(logAttrsFromContext(ctx), attrs...))
+}
+
+func logAttrsFromContext(ctx context.Context) []any {
+	attrs, _ := ctx.Value(logAttrsContextKey{}).([]any)
+	return attrs[:len(attrs):len(attrs)]
+}
+
+// SlogLogger is a GORM logger which writes to the default slog logger, with the attributes of WithLogAttrs.
+// Parameters other than numbers, booleans and times are redacted, as they may contain emotion descriptions.
+type SlogLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewSlogLogger creates a new SlogLogger which logs errors and the queries slower than slowThreshold,
+// 0 disables the slow query log.
+func NewSlogLogger(slowThreshold time.Duration) *SlogLogger {
+	return &SlogLogger{level: logger.Warn, slowThreshold: slowThreshold}
+}
+
+// LogMode implements logger.Interface.
+func (l *SlogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *SlogLogger) log(ctx context.Context, level slog.Level, msg string, attrs ...any) {
+	attrs = append(attrs, logAttrsFromContext(ctx)...)
+	slog.Log(ctx, level, msg, attrs...)
+}
+
+// Info implements logger.Interface.
+func (l *SlogLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Info {
+		l.log(ctx, slog.LevelInfo, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn implements logger.Interface.
+func (l *SlogLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Warn {
+		l.log(ctx, slog.LevelWarn, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error implements logger.Interface.
+func (l *SlogLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Error {
+		l.log(ctx, slog.LevelError, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace implements logger.Interface.
+func (l *SlogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.log(ctx, slog.LevelError, "database query error",
+			"error", err, "elapsed", elapsed, "rows", rows, "sql", sql)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.log(ctx, slog.LevelWarn, "slow database query",
+			"threshold", l.slowThreshold, "elapsed", elapsed, "rows", rows, "sql", sql)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.log(ctx, slog.LevelInfo, "database query", "elapsed", elapsed, "rows", rows, "sql", sql)
+	}
+}
+
+// ParamsFilter implements gorm.ParamsFilter, it keeps the numbers, booleans and times of the logged
+// statements and redacts every other parameter, after resolving driver.Valuer values like the encrypted
+// fields.
+func (l *SlogLogger) ParamsFilter(_ context.Context, sql string, params ...any) (string, []any) {
+	filtered := make([]any, len(params))
+	for i, param := range params {
+		filtered[i] = redactParam(param)
+	}
+
+	return sql, filtered
+}
+
+// redactParam returns the loggable value of a statement parameter.
+func redactParam(param any) any {
+	if valuer, ok := param.(driver.Valuer); ok {
+		if v := reflect.ValueOf(valuer); v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+
+		value, err := valuer.Value()
+		if err != nil {
+			return redacted
+		}
+		param = value
+	}
+
+	v := reflect.ValueOf(param)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return v.Interface()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t
+	}
+
+	return redacted
+}
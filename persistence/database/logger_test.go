@@ -0,0 +1,102 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func captureSlog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+
+	return &buf
+}
+
+func decodeLogLines(s *require.Assertions, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var line map[string]any
+		s.NoError(decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestSlogLoggerTrace(t *testing.T) {
+	s := require.New(t)
+	buf := captureSlog(t)
+
+	ctx := WithLogAttrs(WithLogAttrs(context.Background(), "team_id", "T1"), "user_id", "U1")
+	fc := func() (string, int64) { return "SELECT 1", 1 }
+	l := NewSlogLogger(100 * time.Millisecond)
+
+	l.Trace(ctx, time.Now(), fc, nil)
+	l.Trace(ctx, time.Now(), fc, gorm.ErrRecordNotFound)
+	s.Empty(buf.String())
+
+	l.Trace(ctx, time.Now().Add(-time.Second), fc, nil)
+	l.Trace(ctx, time.Now(), fc, errors.New("boom"))
+	l.LogMode(logger.Info).Trace(ctx, time.Now(), fc, nil)
+	l.LogMode(logger.Silent).Trace(ctx, time.Now(), fc, errors.New("boom"))
+
+	lines := decodeLogLines(s, buf)
+	s.Len(lines, 3)
+	for i, want := range []struct{ level, msg string }{
+		{"WARN", "slow database query"},
+		{"ERROR", "database query error"},
+		{"INFO", "database query"},
+	} {
+		s.Equal(want.level, lines[i]["level"])
+		s.Equal(want.msg, lines[i]["msg"])
+		s.Equal("SELECT 1", lines[i]["sql"])
+		s.Equal("T1", lines[i]["team_id"])
+		s.Equal("U1", lines[i]["user_id"])
+	}
+	s.Equal("boom", lines[1]["error"])
+
+	NewSlogLogger(0).Trace(ctx, time.Now().Add(-time.Hour), fc, nil)
+	s.Empty(buf.String())
+}
+
+func TestSlogLoggerRedactsParams(t *testing.T) {
+	s := require.New(t)
+	buf := captureSlog(t)
+
+	type Note struct {
+		ID    int
+		Text  string
+		Score int
+	}
+
+	conn, err := gorm.Open(SQLiteDialector(filepath.Join(t.TempDir(), "notes.db")), &gorm.Config{
+		Logger: NewSlogLogger(time.Minute).LogMode(logger.Info),
+	})
+	s.NoError(err)
+	s.NoError(conn.AutoMigrate(&Note{}))
+	buf.Reset()
+
+	s.NoError(conn.Create(&Note{Text: "feeling terrible", Score: 12}).Error)
+
+	lines := decodeLogLines(s, buf)
+	s.Len(lines, 1)
+	s.Contains(lines[0]["sql"], "INSERT INTO")
+	s.NotContains(lines[0]["sql"], "feeling terrible")
+	s.Contains(lines[0]["sql"], redacted)
+	s.Contains(lines[0]["sql"], "12")
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnStr(t *testing.T) {
+	s := require.New(t)
+
+	opt := ConnectOption{
+		Dialect:         "postgres",
+		Host:            "db.internal",
+		Port:            5432,
+		DBName:          "cerberus",
+		User:            "bot",
+		Password:        `it's a \ secret`,
+		SSLMode:         "require",
+		Schema:          "cerberus",
+		ApplicationName: "cerberus bot",
+	}
+	config, err := pgx.ParseConfig(opt.ConnStr())
+	s.NoError(err)
+	s.Equal("db.internal", config.Host)
+	s.Equal(`it's a \ secret`, config.Password)
+	s.Equal("cerberus", config.RuntimeParams["search_path"])
+	s.Equal("cerberus bot", config.RuntimeParams["application_name"])
+	s.NotNil(config.TLSConfig)
+
+	opt.SSLMode = ""
+	config, err = pgx.ParseConfig(opt.ConnStr())
+	s.NoError(err)
+	s.Nil(config.TLSConfig)
+
+	opt.URL = "postgres://bot@other.internal/cerberus?sslmode=require"
+	s.Equal(opt.URL, opt.ConnStr())
+}
+
+func TestReadSecretFiles(t *testing.T) {
+	s := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "password")
+	s.NoError(os.WriteFile(path, []byte("secret\n"), 0o600))
+
+	opt := ConnectOption{Password: "ignored", PasswordFile: path}
+	s.NoError(opt.readSecretFiles())
+	s.Equal("secret", opt.Password)
+	s.Empty(opt.URL)
+
+	opt.URLFile = filepath.Join(t.TempDir(), "missing")
+	s.Error(opt.readSecretFiles())
+}
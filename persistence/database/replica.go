@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary returns a copy of the context whose queries go to the primary instead of a read replica,
+// so they see the writes which were just made.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// primaryFromContext reports whether the queries of the context must go to the primary.
+func primaryFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
+
+// useReplicas routes the queries outside transactions to the replicas, if any. Writes, transactions and
+// the reads of contexts from WithPrimary stay on the primary.
+func (d *DB) useReplicas() error {
+	connStrs, err := d.Opt.ReplicaConnStrs()
+	if err != nil {
+		return err
+	}
+	if len(connStrs) == 0 {
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(connStrs))
+	for _, connStr := range connStrs {
+		replicas = append(replicas, d.Opt.dialectorOf(connStr))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})
+	if d.Opt.Dialect == "postgres" {
+		// Zero values keep the defaults of database/sql.
+		if d.Opt.ConnMaxLifetime > 0 {
+			resolver.SetConnMaxLifetime(d.Opt.ConnMaxLifetime)
+		}
+		if d.Opt.ConnMaxIdleTime > 0 {
+			resolver.SetConnMaxIdleTime(d.Opt.ConnMaxIdleTime)
+		}
+		if d.Opt.MaxIdleConns > 0 {
+			resolver.SetMaxIdleConns(d.Opt.MaxIdleConns)
+		}
+		if d.Opt.MaxOpenConns > 0 {
+			resolver.SetMaxOpenConns(d.Opt.MaxOpenConns)
+		}
+	}
+
+	// Registered first, so they run before the resolver, which also runs before all other callbacks.
+	callbacks := d.db.Callback()
+	for _, err := range []error{
+		callbacks.Query().Before("*").Register("cerberus:read_your_writes", readYourWrites),
+		callbacks.Row().Before("*").Register("cerberus:read_your_writes", readYourWrites),
+		callbacks.Raw().Before("*").Register("cerberus:read_your_writes", readYourWrites),
+	} {
+		if err != nil {
+			return fmt.Errorf("register read your writes callback error: %w", err)
+		}
+	}
+
+	if err := d.db.Use(resolver); err != nil {
+		return fmt.Errorf("use replicas error: %w", err)
+	}
+
+	return nil
+}
+
+// readYourWrites routes the statement to the primary if its context must see the preceding writes.
+func readYourWrites(tx *gorm.DB) {
+	if primaryFromContext(tx.Statement.Context) {
+		dbresolver.Write.ModifyStatement(tx.Statement)
+	}
+}
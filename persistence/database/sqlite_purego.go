@@ -0,0 +1,22 @@
+//go:build !cgo || sqlite_purego
+
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLiteDialector returns the dialector of the SQLite dsn, using the pure Go driver, so the binary
+// can be built with CGO_ENABLED=0 or the sqlite_purego tag.
+func SQLiteDialector(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
+
+// SQLiteDSN returns the dsn of the dialector, ok is false if it is not a SQLite dialector.
+func SQLiteDSN(dialector gorm.Dialector) (dsn string, ok bool) {
+	if d, ok := dialector.(*sqlite.Dialector); ok {
+		return d.DSN, true
+	}
+	return "", false
+}
@@ -3,7 +3,9 @@ package database
 import (
 	"fmt"
 	"log/slog"
+	"os"
 	"sync/atomic"
+	"testing"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -28,25 +30,58 @@ var (
 	}
 )
 
+// TestingPostgresEnv is the environment variable which adds Postgres to TestingDialects, e.g. TEST_POSTGRES=true
+// with the database of deploy/dev/docker-compose.yaml.
+const TestingPostgresEnv = "TEST_POSTGRES"
+
+// TestingDialects returns the connection options which persistence tests run against, SQLite in memory
+// and Postgres if TestingPostgresEnv is set.
+func TestingDialects() []ConnectOption {
+	opts := []ConnectOption{SQLiteOpt}
+	if os.Getenv(TestingPostgresEnv) != "" {
+		opts = append(opts, PostgresOpt)
+	}
+
+	return opts
+}
+
+// TestingEachDialect runs fn as a subtest named after each of TestingDialects, with a new isolated database.
+func TestingEachDialect(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for _, opt := range TestingDialects() {
+		t.Run(opt.Dialect, func(t *testing.T) {
+			d, finalize := TestingInitialize(opt)
+			defer finalize()
+
+			fn(t, d.GetDB())
+		})
+	}
+}
+
 var cnt atomic.Int32
 
 func randomDBName() string {
 	return fmt.Sprintf("testing_%v_%d", time.Now().UnixNano(), cnt.Add(1))
 }
 
-// TestingInitialize creates new db for testing.
-func TestingInitialize(opt ConnectOption) (funcFinalize func()) {
+// TestingInitialize creates new db for testing, isolated from the other databases it created, so tests
+// can run in parallel. SQLiteOpt is replaced by a new in-memory database.
+func TestingInitialize(opt ConnectOption) (d *DB, funcFinalize func()) {
 	opt.Config.DisableForeignKeyConstraintWhenMigrating = true
 	opt.Testing = true
 
 	if opt.Dialect != "postgres" {
-		if err := Initialize(opt); err != nil {
+		if opt.Host == SQLiteOpt.Host {
+			opt.Host = "file:" + randomDBName() + "?mode=memory&cache=shared"
+		}
+
+		d, err := New(opt)
+		if err != nil {
 			slog.Error("Failed to initialize database", "error", err)
 			panic(err)
 		}
 
-		return func() {
-			if err := Finalize(); err != nil {
+		return d, func() {
+			if err := d.Close(); err != nil {
 				slog.Error("Failed to finalize database", "error", err)
 				panic(err)
 			}
@@ -69,13 +104,14 @@ func TestingInitialize(opt ConnectOption) (funcFinalize func()) {
 	}
 
 	opt.DBName = randomDBName
-	if err := Initialize(opt); err != nil {
+	d, err = New(opt)
+	if err != nil {
 		slog.Error("Failed to initialize database", "error", err)
 		panic(err)
 	}
 
 	funcFinalize = func() {
-		if err := Finalize(); err != nil {
+		if err := d.Close(); err != nil {
 			slog.Error("Failed to finalize database", "error", err)
 			panic(err)
 		}
@@ -84,9 +120,18 @@ func TestingInitialize(opt ConnectOption) (funcFinalize func()) {
 			slog.Error("Failed to drop test database", "error", err)
 			panic(err)
 		}
+
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil {
+			slog.Error("Failed to close postgres connection", "error", err)
+			panic(err)
+		}
 	}
 
-	if err := GetDB().Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error; err != nil {
+	if err := d.GetDB().Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error; err != nil {
 		slog.Error("Failed to install UUID extension", "error", err)
 		panic(err)
 	}
@@ -0,0 +1,236 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// keySize is the size of both key encryption keys and data encryption keys, which selects AES-256.
+const keySize = 32
+
+// prefix marks a value encrypted by Keyring, values without it are treated as plain text. The ciphertext of
+// v2 values is bound to its associated data, v1 values were encrypted without it and are only decrypted.
+const (
+	prefix   = "enc:v2:"
+	prefixV1 = "enc:v1:"
+)
+
+// ErrUnknownKey is returned when a value was encrypted by a key which is not in the keyring.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Keyring holds the key encryption keys by ID. Values are encrypted with the active key and
+// decrypted with the key recorded in the value, so keys can be rotated without downtime.
+//
+// Every value is encrypted with its own random data key using AES-GCM, and the data key is
+// encrypted (wrapped) with the key encryption key, i.e. envelope encryption. The encoded value is
+//
+//	enc:v2:<key ID>:<base64 wrapped data key>:<base64 ciphertext>
+//
+// The ciphertext is authenticated together with associated data, e.g. the table and column of the value,
+// so a value copied to another place does not decrypt there.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring, every key must be 32 bytes.
+func NewKeyring(activeKeyID string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKeyID)
+	}
+
+	k := &Keyring{activeKeyID: activeKeyID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+// keyFile is the format of the key file, keys are base64 encoded.
+type keyFile struct {
+	ActiveKeyID string            `json:"active_key_id"`
+	Keys        map[string]string `json:"keys"`
+}
+
+// LoadKeyringFile loads a keyring from a JSON file like
+//
+//	{"active_key_id": "2026-10", "keys": {"2026-10": "<base64 of 32 random bytes>"}}
+func LoadKeyringFile(path string) (*Keyring, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	var f keyFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse key file: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyring(f.ActiveKeyID, keys)
+}
+
+// ActiveKeyID returns the ID of the key which encrypts new values.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// Encrypt encrypts the plaintext with a new data key wrapped by the active key, bound to the associated
+// data, which is not encrypted but must be passed to Decrypt as it was.
+func (k *Keyring) Encrypt(plaintext, associatedData []byte) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("generate data key: %w", err)
+	}
+
+	wrappedKey, err := seal(k.keys[k.activeKeyID], dataKey, nil)
+	if err != nil {
+		return "", fmt.Errorf("wrap data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(aead, plaintext, associatedData)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: %w", err)
+	}
+
+	return prefix + k.activeKeyID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt with the same associated data. v1 values ignore it.
+func (k *Keyring) Decrypt(value string, associatedData []byte) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		// v1 values were encrypted without associated data.
+		encoded, ok = strings.CutPrefix(value, prefixV1)
+		associatedData = nil
+	}
+
+	parts := strings.Split(encoded, ":")
+	if !ok || len(parts) != 3 {
+		return nil, fmt.Errorf("malformed encrypted value")
+	}
+
+	keyID := parts[0]
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode data key: %w", err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+
+	dataKey, err := open(kek, wrappedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(aead, ciphertext, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether the value was produced by Encrypt, in any version.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) || strings.HasPrefix(value, prefixV1)
+}
+
+// KeyID returns the ID of the key which encrypted the value.
+func KeyID(value string) (string, bool) {
+	encoded, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		encoded, ok = strings.CutPrefix(value, prefixV1)
+	}
+	if !ok {
+		return "", false
+	}
+
+	keyID, _, ok := strings.Cut(encoded, ":")
+	return keyID, ok
+}
+
+// IsCurrent reports whether the value is encrypted by the active key in the current version, i.e. it does
+// not need to be re-encrypted.
+func (k *Keyring) IsCurrent(value string) bool {
+	keyID, ok := KeyID(value)
+	return ok && strings.HasPrefix(value, prefix) && keyID == k.activeKeyID
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext and prepends the random nonce.
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// open decrypts a value produced by seal.
+func open(aead cipher.AEAD, ciphertext, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
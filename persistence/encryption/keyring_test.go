@@ -0,0 +1,102 @@
+package encryption_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	s := require.New(t)
+
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+
+	oldKeyring, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey})
+	s.NoError(err)
+
+	value, err := oldKeyring.Encrypt([]byte("feeling blue"), []byte("emotions.description"))
+	s.NoError(err)
+	s.True(encryption.IsEncrypted(value))
+	s.NotContains(value, "feeling blue")
+
+	keyID, ok := encryption.KeyID(value)
+	s.True(ok)
+	s.Equal("old", keyID)
+
+	rotated, err := encryption.NewKeyring("new", map[string][]byte{"old": oldKey, "new": newKey})
+	s.NoError(err)
+
+	plaintext, err := rotated.Decrypt(value, []byte("emotions.description"))
+	s.NoError(err)
+	s.Equal("feeling blue", string(plaintext))
+
+	value, err = rotated.Encrypt(plaintext, []byte("emotions.description"))
+	s.NoError(err)
+	keyID, _ = encryption.KeyID(value)
+	s.Equal("new", keyID)
+
+	_, err = oldKeyring.Decrypt(value, []byte("emotions.description"))
+	s.ErrorIs(err, encryption.ErrUnknownKey)
+
+	_, err = encryption.NewKeyring("new", map[string][]byte{"new": []byte("too short")})
+	s.Error(err)
+}
+
+func TestKeyringAssociatedData(t *testing.T) {
+	s := require.New(t)
+
+	key := bytes.Repeat([]byte{1}, 32)
+	keyring, err := encryption.NewKeyring("k1", map[string][]byte{"k1": key})
+	s.NoError(err)
+
+	value, err := keyring.Encrypt([]byte("feeling blue"), []byte("emotions.description"))
+	s.NoError(err)
+	s.True(keyring.IsCurrent(value))
+
+	// A value copied to another column does not decrypt there.
+	_, err = keyring.Decrypt(value, []byte("emotions.task"))
+	s.Error(err)
+
+	plaintext, err := keyring.Decrypt(value, []byte("emotions.description"))
+	s.NoError(err)
+	s.Equal("feeling blue", string(plaintext))
+
+	// v1 values were encrypted without associated data, they are still read but need to be re-encrypted.
+	legacy := encryptV1(s, "k1", key, []byte("written by v1"))
+	s.True(encryption.IsEncrypted(legacy))
+	s.False(keyring.IsCurrent(legacy))
+
+	keyID, ok := encryption.KeyID(legacy)
+	s.True(ok)
+	s.Equal("k1", keyID)
+
+	plaintext, err = keyring.Decrypt(legacy, []byte("emotions.description"))
+	s.NoError(err)
+	s.Equal("written by v1", string(plaintext))
+}
+
+// encryptV1 encrypts the plaintext in the v1 format, which has no associated data.
+func encryptV1(s *require.Assertions, keyID string, key, plaintext []byte) string {
+	seal := func(key, plaintext []byte) string {
+		block, err := aes.NewCipher(key)
+		s.NoError(err)
+		aead, err := cipher.NewGCM(block)
+		s.NoError(err)
+
+		nonce := make([]byte, aead.NonceSize())
+		_, err = rand.Read(nonce)
+		s.NoError(err)
+
+		return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil))
+	}
+
+	dataKey := bytes.Repeat([]byte{9}, 32)
+	return "enc:v1:" + keyID + ":" + seal(key, dataKey) + ":" + seal(dataKey, plaintext)
+}
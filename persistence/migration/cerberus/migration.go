@@ -4,9 +4,32 @@ import (
 	"github.com/go-gormigrate/gormigrate/v2"
 
 	v0 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v0"
+	v1 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v1"
+	v10 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v10"
+	v2 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v2"
+	v3 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v3"
+	v4 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v4"
+	v5 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v5"
+	v6 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v6"
+	v7 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v7"
+	v8 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v8"
+	v9 "github.com/omegaatt36/cerberus/persistence/migration/cerberus/v9"
 )
 
 // MigrationList is list of migrations.
 var MigrationList = []*gormigrate.Migration{
 	&v0.CreateEmotion,
+	&v1.CreateAuditLog,
+	&v2.CreateBuddy,
+	&v3.AddEmotionDeletedAt,
+	&v4.AddEmotionWorkspace,
+	&v4.CreateEmotionDailyStat,
+	&v5.AddEmotionTimeZone,
+	&v5.AddEmotionDailyStatUserIndex,
+	&v6.CreateAIUsage,
+	&v7.CreateRateLimitBucket,
+	&v8.CreateCacheEntry,
+	&v9.AddEmotionVersion,
+	&v10.ClearUnscoredEmotionScores,
+	&v10.AddEmotionDailyStatScoredCount,
 }
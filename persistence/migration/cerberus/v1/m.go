@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AuditLog represents an audit trail entry.
+type AuditLog struct {
+	ID        int `gorm:"primaryKey"`
+	CreatedAt time.Time
+	Action    string `gorm:"type:text;not null;index:idx_audit_log_action"`
+	UserID    string `gorm:"type:text;not null;default:''"`
+	Detail    string `gorm:"type:text;not null;default:''"`
+}
+
+// TableName returns the table name.
+func (a AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// CreateAuditLog defines the migration which creates the audit log table.
+var CreateAuditLog = gormigrate.Migration{
+	ID: "2026-10-19:create-audit-log",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&AuditLog{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&AuditLog{})
+	},
+}
@@ -0,0 +1,84 @@
+package v10
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Emotion represents a emotion.
+type Emotion struct {
+	ID              int       `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index:idx_emotion_team_id_created_at,priority:2"`
+	UpdatedAt       time.Time
+	TeamID          string `gorm:"type:text;not null;default:'';index:idx_emotion_team_id_created_at,priority:1"`
+	ChannelID       string `gorm:"type:text;not null;default:''"`
+	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	TimeZone        string `gorm:"type:text;not null;default:''"`
+	Emoji           string `gorm:"type:text;not null"`
+	Description     string `gorm:"type:text;not null;default:''"`
+	Score           *int   `gorm:"type:integer"`
+	Task            string `gorm:"type:text"`
+	TaskCompletedAt *time.Time
+	Version         int            `gorm:"type:integer;not null;default:1"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name.
+func (e Emotion) TableName() string {
+	return "emotions"
+}
+
+// EmotionDailyStat represents the aggregated emotions of a user in a channel on a day.
+type EmotionDailyStat struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TeamID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:1;index:idx_emotion_daily_stat_user,priority:1"`
+	ChannelID      string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:2"`
+	UserID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:3;index:idx_emotion_daily_stat_user,priority:2"`
+	Date           string  `gorm:"type:varchar(10);not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:4;index:idx_emotion_daily_stat_user,priority:3"`
+	Count          int     `gorm:"type:integer;not null"`
+	ScoredCount    int     `gorm:"type:integer;not null;default:0"`
+	AvgScore       float64 `gorm:"not null"`
+	MinScore       int     `gorm:"type:integer;not null"`
+	MaxScore       int     `gorm:"type:integer;not null"`
+	TasksCompleted int     `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (s EmotionDailyStat) TableName() string {
+	return "emotion_daily_stats"
+}
+
+// ClearUnscoredEmotionScores defines the migration which stores no score instead of 0 for the emotions
+// which were never scored. They are told apart by their missing task, so emotions whose task was stored
+// encrypted keep their 0 score.
+var ClearUnscoredEmotionScores = gormigrate.Migration{
+	ID: "2026-10-19:clear-unscored-emotion-scores",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Model(&Emotion{}).Unscoped().
+			Where("score = 0 AND (task IS NULL OR task = '')").
+			Update("score", nil).Error
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Model(&Emotion{}).Unscoped().Where("score IS NULL").Update("score", 0).Error
+	},
+}
+
+// AddEmotionDailyStatScoredCount defines the migration which counts the scored emotions of the daily
+// statistics, which the scores are computed from. Existing statistics count every emotion as scored
+// until they are rebuilt.
+var AddEmotionDailyStatScoredCount = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-daily-stat-scored-count",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&EmotionDailyStat{}, "ScoredCount"); err != nil {
+			return err
+		}
+		return tx.Model(&EmotionDailyStat{}).Where("1 = 1").Update("scored_count", gorm.Expr("count")).Error
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&EmotionDailyStat{}, "ScoredCount")
+	},
+}
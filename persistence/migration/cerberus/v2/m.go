@@ -0,0 +1,38 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Buddy represents a user who gets notified when another user's mood stays low.
+type Buddy struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         string `gorm:"type:text;not null;uniqueIndex:idx_buddy_user_id"`
+	BuddyUserID    string `gorm:"type:text;not null;index:idx_buddy_buddy_user_id"`
+	Status         string `gorm:"type:text;not null"`
+	Threshold      int    `gorm:"type:integer;not null"`
+	Days           int    `gorm:"type:integer;not null"`
+	ConfirmedAt    *time.Time
+	LastNotifiedAt *time.Time
+}
+
+// TableName returns the table name.
+func (b Buddy) TableName() string {
+	return "buddies"
+}
+
+// CreateBuddy defines the migration which creates the buddy table.
+var CreateBuddy = gormigrate.Migration{
+	ID: "2026-10-19:create-buddy",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&Buddy{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&Buddy{})
+	},
+}
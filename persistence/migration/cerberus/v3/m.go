@@ -0,0 +1,44 @@
+package v3
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Emotion represents a emotion.
+type Emotion struct {
+	ID              int `gorm:"primaryKey"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	Emoji           string `gorm:"type:text;not null"`
+	Description     string `gorm:"type:text;not null;default:''"`
+	Score           int    `gorm:"type:integer"`
+	Task            string `gorm:"type:text"`
+	TaskCompletedAt *time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name.
+func (e Emotion) TableName() string {
+	return "emotions"
+}
+
+// AddEmotionDeletedAt defines the migration which enables soft delete on emotions.
+var AddEmotionDeletedAt = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-deleted-at",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&Emotion{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&Emotion{}, "DeletedAt")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&Emotion{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&Emotion{}, "DeletedAt")
+	},
+}
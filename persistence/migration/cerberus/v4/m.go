@@ -0,0 +1,85 @@
+package v4
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Emotion represents a emotion.
+type Emotion struct {
+	ID              int       `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index:idx_emotion_team_id_created_at,priority:2"`
+	UpdatedAt       time.Time
+	TeamID          string `gorm:"type:text;not null;default:'';index:idx_emotion_team_id_created_at,priority:1"`
+	ChannelID       string `gorm:"type:text;not null;default:''"`
+	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	Emoji           string `gorm:"type:text;not null"`
+	Description     string `gorm:"type:text;not null;default:''"`
+	Score           int    `gorm:"type:integer"`
+	Task            string `gorm:"type:text"`
+	TaskCompletedAt *time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name.
+func (e Emotion) TableName() string {
+	return "emotions"
+}
+
+// EmotionDailyStat represents the aggregated emotions of a user in a channel on a day.
+type EmotionDailyStat struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TeamID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:1"`
+	ChannelID      string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:2"`
+	UserID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:3"`
+	Date           string  `gorm:"type:varchar(10);not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:4"`
+	Count          int     `gorm:"type:integer;not null"`
+	AvgScore       float64 `gorm:"not null"`
+	MinScore       int     `gorm:"type:integer;not null"`
+	MaxScore       int     `gorm:"type:integer;not null"`
+	TasksCompleted int     `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (s EmotionDailyStat) TableName() string {
+	return "emotion_daily_stats"
+}
+
+// AddEmotionWorkspace defines the migration which records the workspace and channel of emotions.
+var AddEmotionWorkspace = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-workspace",
+	Migrate: func(tx *gorm.DB) error {
+		for _, field := range []string{"TeamID", "ChannelID"} {
+			if err := tx.Migrator().AddColumn(&Emotion{}, field); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().CreateIndex(&Emotion{}, "idx_emotion_team_id_created_at")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&Emotion{}, "idx_emotion_team_id_created_at"); err != nil {
+			return err
+		}
+		for _, field := range []string{"ChannelID", "TeamID"} {
+			if err := tx.Migrator().DropColumn(&Emotion{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// CreateEmotionDailyStat defines the migration which creates the daily rollup of emotions.
+var CreateEmotionDailyStat = gormigrate.Migration{
+	ID: "2026-10-19:create-emotion-daily-stat",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&EmotionDailyStat{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&EmotionDailyStat{})
+	},
+}
@@ -0,0 +1,74 @@
+package v5
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Emotion represents a emotion.
+type Emotion struct {
+	ID              int       `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index:idx_emotion_team_id_created_at,priority:2"`
+	UpdatedAt       time.Time
+	TeamID          string `gorm:"type:text;not null;default:'';index:idx_emotion_team_id_created_at,priority:1"`
+	ChannelID       string `gorm:"type:text;not null;default:''"`
+	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	TimeZone        string `gorm:"type:text;not null;default:''"`
+	Emoji           string `gorm:"type:text;not null"`
+	Description     string `gorm:"type:text;not null;default:''"`
+	Score           int    `gorm:"type:integer"`
+	Task            string `gorm:"type:text"`
+	TaskCompletedAt *time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name.
+func (e Emotion) TableName() string {
+	return "emotions"
+}
+
+// EmotionDailyStat represents the aggregated emotions of a user in a channel on a day.
+type EmotionDailyStat struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TeamID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:1;index:idx_emotion_daily_stat_user,priority:1"`
+	ChannelID      string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:2"`
+	UserID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:3;index:idx_emotion_daily_stat_user,priority:2"`
+	Date           string  `gorm:"type:varchar(10);not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:4;index:idx_emotion_daily_stat_user,priority:3"`
+	Count          int     `gorm:"type:integer;not null"`
+	AvgScore       float64 `gorm:"not null"`
+	MinScore       int     `gorm:"type:integer;not null"`
+	MaxScore       int     `gorm:"type:integer;not null"`
+	TasksCompleted int     `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (s EmotionDailyStat) TableName() string {
+	return "emotion_daily_stats"
+}
+
+// AddEmotionTimeZone defines the migration which records the time zone of the user on emotions,
+// so the daily statistics follow the day of the user.
+var AddEmotionTimeZone = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-time-zone",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&Emotion{}, "TimeZone")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&Emotion{}, "TimeZone")
+	},
+}
+
+// AddEmotionDailyStatUserIndex defines the migration which indexes the daily statistics by user.
+var AddEmotionDailyStatUserIndex = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-daily-stat-user-index",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateIndex(&EmotionDailyStat{}, "idx_emotion_daily_stat_user")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropIndex(&EmotionDailyStat{}, "idx_emotion_daily_stat_user")
+	},
+}
@@ -0,0 +1,37 @@
+package v6
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AIUsage represents the tokens used by an AI service call.
+type AIUsage struct {
+	ID               int       `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index:idx_ai_usage_team_id_created_at,priority:2;index:idx_ai_usage_user_id_created_at,priority:2"`
+	TeamID           string    `gorm:"type:text;not null;default:'';index:idx_ai_usage_team_id_created_at,priority:1"`
+	UserID           string    `gorm:"type:text;not null;default:'';index:idx_ai_usage_user_id_created_at,priority:1"`
+	Provider         string    `gorm:"type:text;not null"`
+	Model            string    `gorm:"type:text;not null"`
+	Method           string    `gorm:"type:text;not null"`
+	PromptTokens     int       `gorm:"type:integer;not null"`
+	CompletionTokens int       `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (u AIUsage) TableName() string {
+	return "ai_usage"
+}
+
+// CreateAIUsage defines the migration which creates the token usage of AI service calls.
+var CreateAIUsage = gormigrate.Migration{
+	ID: "2026-10-19:create-ai-usage",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&AIUsage{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&AIUsage{})
+	},
+}
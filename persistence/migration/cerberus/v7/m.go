@@ -0,0 +1,31 @@
+package v7
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// RateLimitBucket represents the token bucket of a rate limit key.
+type RateLimitBucket struct {
+	BucketKey string  `gorm:"type:text;primaryKey"`
+	Tokens    float64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName returns the table name.
+func (b RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// CreateRateLimitBucket defines the migration which creates the rate limit buckets shared by replicas.
+var CreateRateLimitBucket = gormigrate.Migration{
+	ID: "2026-10-19:create-rate-limit-bucket",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&RateLimitBucket{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&RateLimitBucket{})
+	},
+}
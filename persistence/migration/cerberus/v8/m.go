@@ -0,0 +1,31 @@
+package v8
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// CacheEntry represents a cached value.
+type CacheEntry struct {
+	CacheKey  string    `gorm:"type:text;primaryKey"`
+	Value     string    `gorm:"type:text;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the table name.
+func (e CacheEntry) TableName() string {
+	return "cache_entries"
+}
+
+// CreateCacheEntry defines the migration which creates the cache shared by replicas.
+var CreateCacheEntry = gormigrate.Migration{
+	ID: "2026-10-19:create-cache-entry",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&CacheEntry{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&CacheEntry{})
+	},
+}
@@ -0,0 +1,43 @@
+package v9
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Emotion represents a emotion.
+type Emotion struct {
+	ID              int       `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index:idx_emotion_team_id_created_at,priority:2"`
+	UpdatedAt       time.Time
+	TeamID          string `gorm:"type:text;not null;default:'';index:idx_emotion_team_id_created_at,priority:1"`
+	ChannelID       string `gorm:"type:text;not null;default:''"`
+	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	TimeZone        string `gorm:"type:text;not null;default:''"`
+	Emoji           string `gorm:"type:text;not null"`
+	Description     string `gorm:"type:text;not null;default:''"`
+	Score           int    `gorm:"type:integer"`
+	Task            string `gorm:"type:text"`
+	TaskCompletedAt *time.Time
+	Version         int            `gorm:"type:integer;not null;default:1"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name.
+func (e Emotion) TableName() string {
+	return "emotions"
+}
+
+// AddEmotionVersion defines the migration which adds the version of emotions, which updates compare
+// and swap so concurrent updates don't overwrite each other.
+var AddEmotionVersion = gormigrate.Migration{
+	ID: "2026-10-19:add-emotion-version",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&Emotion{}, "Version")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&Emotion{}, "Version")
+	},
+}
@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+)
+
+// advisoryLockKey is the Postgres advisory lock key which guards migrations, "cerb" in ASCII.
+const advisoryLockKey = 0x63657262
+
+// ErrUnknownMigrations is returned when the database has migrations applied which the binary does not know,
+// i.e. the schema is newer than the binary.
+var ErrUnknownMigrations = errors.New("database has migrations unknown to this binary")
+
+// CheckUnknown returns ErrUnknownMigrations if the database has migrations applied which are not in the
+// migration list.
+func (m *Migrator) CheckUnknown() error {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		delete(applied, migration.ID)
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	unknown := make([]string, 0, len(applied))
+	for id := range applied {
+		unknown = append(unknown, id)
+	}
+	slices.Sort(unknown)
+
+	return fmt.Errorf("%w: %s", ErrUnknownMigrations, strings.Join(unknown, ", "))
+}
+
+// AutoUpgrade upgrades db schema version while holding the migration lock, so replicas starting at the
+// same time do not race. It refuses to upgrade if the schema is newer than the binary.
+func (m *Migrator) AutoUpgrade(ctx context.Context) error {
+	return m.WithLock(ctx, func(mg *Migrator) error {
+		if err := mg.CheckUnknown(); err != nil {
+			return err
+		}
+
+		return mg.Upgrade()
+	})
+}
+
+// WithLock calls fn with a migrator of the primary while holding the migration lock, which is a Postgres
+// advisory lock, or a file lock next to the SQLite database. Every change of the schema should run in it.
+func (m *Migrator) WithLock(ctx context.Context, fn func(*Migrator) error) error {
+	// Migrations must not read stale state from a read replica.
+	db := m.db.WithContext(database.WithPrimary(ctx))
+	mg := NewMigrator(db, m.models, m.migrations)
+
+	if dsn, ok := database.SQLiteDSN(db.Dialector); ok {
+		path, ok := sqliteFilePath(dsn)
+		if !ok {
+			// In-memory databases are private to the process.
+			return fn(mg)
+		}
+
+		unlock, err := lockFile(path + ".migration.lock")
+		if err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer func() {
+			if err := unlock(); err != nil {
+				slog.Error("failed to unlock migrations", slog.String("error", err.Error()))
+			}
+		}()
+
+		return fn(mg)
+	}
+
+	// Advisory locks belong to the session, so the lock is held by a dedicated connection of the primary,
+	// which bypasses the GORM callbacks that may route statements to a read replica.
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to lock migrations: %v", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to lock migrations: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+		return fmt.Errorf("failed to lock migrations: %v", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey); err != nil {
+			slog.Error("failed to unlock migrations", slog.String("error", err.Error()))
+		}
+	}()
+
+	return fn(mg)
+}
+
+// sqliteFilePath returns the file path of the SQLite DSN, ok is false for in-memory databases.
+func sqliteFilePath(dsn string) (path string, ok bool) {
+	path, _, _ = strings.Cut(dsn, "?")
+	path = strings.TrimPrefix(path, "file:")
+	if path == "" || path == ":memory:" || strings.Contains(dsn, "mode=memory") {
+		return "", false
+	}
+
+	return path, true
+}
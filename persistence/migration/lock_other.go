@@ -0,0 +1,11 @@
+//go:build !unix
+
+package migration
+
+import "log/slog"
+
+// lockFile does not lock on platforms without flock, SQLite replicas should not share a database there.
+func lockFile(path string) (unlock func() error, err error) {
+	slog.Warn("file locks are not supported on this platform, migrations are not locked", slog.String("path", path))
+	return func() error { return nil }, nil
+}
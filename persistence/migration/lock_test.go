@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+)
+
+func TestSQLiteFilePath(t *testing.T) {
+	s := require.New(t)
+
+	for dsn, want := range map[string]string{
+		"cerberus.db":                  "cerberus.db",
+		"file:/data/cerberus.db?_fk=1": "/data/cerberus.db",
+		":memory:":                     "",
+		"file::memory:?cache=shared":   "",
+		"file:cerberus.db?mode=memory": "",
+	} {
+		path, ok := sqliteFilePath(dsn)
+		s.Equal(want, path, dsn)
+		s.Equal(want != "", ok, dsn)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	s := require.New(t)
+	path := filepath.Join(t.TempDir(), "migration.lock")
+
+	unlock, err := lockFile(path)
+	s.NoError(err)
+
+	// The second lock is taken by another goroutine, which must not call FailNow, so it sends its result.
+	type result struct {
+		unlock func() error
+		err    error
+	}
+	locked := make(chan result, 1)
+	go func() {
+		unlock, err := lockFile(path)
+		locked <- result{unlock: unlock, err: err}
+	}()
+
+	select {
+	case <-locked:
+		s.FailNow("the lock is held twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.NoError(unlock())
+	second := <-locked
+	s.NoError(second.err)
+	s.NoError(second.unlock())
+}
+
+func TestAutoUpgrade(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	db, err := gorm.Open(database.SQLiteDialector(filepath.Join(t.TempDir(), "cerberus.db")), &gorm.Config{Logger: logger.Discard})
+	s.NoError(err)
+
+	type Note struct {
+		ID   int
+		Text string
+	}
+	type Tag struct {
+		ID   int
+		Name string
+	}
+	migrations := []*gormigrate.Migration{{
+		ID: "1",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&Note{})
+		},
+	}, {
+		ID: "2",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&Tag{})
+		},
+	}}
+
+	s.NoError(NewMigrator(db, nil, migrations).AutoUpgrade(ctx))
+	s.True(db.Migrator().HasTable(&Tag{}))
+
+	// An older binary knows only the first migration.
+	s.ErrorIs(NewMigrator(db, nil, migrations[:1]).AutoUpgrade(ctx), ErrUnknownMigrations)
+}
@@ -1,11 +1,14 @@
 package migration
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var migrationOptions = gormigrate.Options{
@@ -50,3 +53,193 @@ func (m *Migrator) Rollback() error {
 	slog.Info("rollback to last")
 	return nil
 }
+
+// MigrationStatus is the state of a migration.
+type MigrationStatus struct {
+	ID      string
+	Applied bool
+}
+
+// Status lists the migrations in order, with whether they are applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		_, ok := applied[migration.ID]
+		statuses = append(statuses, MigrationStatus{ID: migration.ID, Applied: ok})
+	}
+
+	return statuses, nil
+}
+
+// appliedIDs returns the IDs recorded in the migration table.
+func (m *Migrator) appliedIDs() (map[string]struct{}, error) {
+	applied := make(map[string]struct{})
+	if !m.db.Migrator().HasTable(gormigrate.DefaultOptions.TableName) {
+		return applied, nil
+	}
+
+	var ids []string
+	if err := m.db.Table(gormigrate.DefaultOptions.TableName).
+		Pluck(gormigrate.DefaultOptions.IDColumnName, &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %v", err)
+	}
+
+	for _, id := range ids {
+		applied[id] = struct{}{}
+	}
+
+	return applied, nil
+}
+
+// indexOf returns the index of the migration, or an error if it is unknown.
+func (m *Migrator) indexOf(id string) (int, error) {
+	for i, migration := range m.migrations {
+		if migration.ID == id {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown migration %q", id)
+}
+
+// MigrateTo applies the pending migrations up to and including the migration id.
+func (m *Migrator) MigrateTo(id string) error {
+	if _, err := m.indexOf(id); err != nil {
+		return err
+	}
+
+	mg := gormigrate.New(m.db, &migrationOptions, m.migrations)
+	if err := mg.MigrateTo(id); err != nil {
+		return fmt.Errorf("migrate to %s: %w", id, err)
+	}
+
+	slog.Info(fmt.Sprintf("upgraded to version \"%s\"", id))
+	return nil
+}
+
+// RollbackTo rollbacks the applied migrations after the migration id, which stays applied.
+func (m *Migrator) RollbackTo(id string) error {
+	if _, err := m.indexOf(id); err != nil {
+		return err
+	}
+
+	mg := gormigrate.New(m.db, &migrationOptions, m.migrations)
+	if err := mg.RollbackTo(id); err != nil {
+		return fmt.Errorf("rollback to %s: %w", id, err)
+	}
+
+	slog.Info(fmt.Sprintf("rollback to version \"%s\"", id))
+	return nil
+}
+
+// PlannedMigration is a migration that would run, with the SQL statements it would execute.
+type PlannedMigration struct {
+	ID  string
+	SQL []string
+}
+
+// DryRunMigrateTo returns the pending migrations up to and including the migration id, an empty id
+// means all of them, without running them. The statements are built in a GORM DryRun session, so
+// migrations which inspect the schema may differ from what actually runs.
+func (m *Migrator) DryRunMigrateTo(id string) ([]PlannedMigration, error) {
+	last := len(m.migrations) - 1
+	if id != "" {
+		var err error
+		if last, err = m.indexOf(id); err != nil {
+			return nil, err
+		}
+	}
+
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []PlannedMigration
+	for _, migration := range m.migrations[:last+1] {
+		if _, ok := applied[migration.ID]; ok {
+			continue
+		}
+
+		statements, err := m.dryRun(migration.Migrate)
+		if err != nil {
+			return nil, fmt.Errorf("dry run %s: %w", migration.ID, err)
+		}
+		plans = append(plans, PlannedMigration{ID: migration.ID, SQL: statements})
+	}
+
+	return plans, nil
+}
+
+// DryRunRollbackTo returns the applied migrations after the migration id in rollback order,
+// without rolling them back.
+func (m *Migrator) DryRunRollbackTo(id string) ([]PlannedMigration, error) {
+	index, err := m.indexOf(id)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.appliedIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []PlannedMigration
+	for i := len(m.migrations) - 1; i > index; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.ID]; !ok {
+			continue
+		}
+		if migration.Rollback == nil {
+			return nil, fmt.Errorf("dry run %s: %w", migration.ID, gormigrate.ErrRollbackImpossible)
+		}
+
+		statements, err := m.dryRun(migration.Rollback)
+		if err != nil {
+			return nil, fmt.Errorf("dry run %s: %w", migration.ID, err)
+		}
+		plans = append(plans, PlannedMigration{ID: migration.ID, SQL: statements})
+	}
+
+	return plans, nil
+}
+
+// dryRun calls fn with a DryRun session and returns the statements it built.
+func (m *Migrator) dryRun(fn func(*gorm.DB) error) (statements []string, err error) {
+	// Some dialect migrators read the schema back, e.g. SQLite recreates the table to drop a column,
+	// which panics as DryRun sessions return no rows.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("the statements cannot be built without running them on %s: %v", m.db.Dialector.Name(), r)
+		}
+	}()
+
+	recorder := &sqlRecorder{Interface: m.db.Logger}
+	if err := fn(m.db.Session(&gorm.Session{DryRun: true, Logger: recorder})); err != nil {
+		return nil, err
+	}
+
+	return recorder.statements, nil
+}
+
+// sqlRecorder is a GORM logger which records the traced statements.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+// LogMode implements logger.Interface.
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+// Trace implements logger.Interface.
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
@@ -3,7 +3,10 @@ package migration_test
 import (
 	"testing"
 
+	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"github.com/omegaatt36/cerberus/persistence/database"
 	"github.com/omegaatt36/cerberus/persistence/migration"
@@ -11,14 +14,119 @@ import (
 )
 
 func TestMigrateAPI(t *testing.T) {
-	s := assert.New(t)
+	t.Parallel()
 
-	finalize := database.TestingInitialize(database.PostgresOpt)
-	defer finalize()
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := assert.New(t)
 
-	db := database.GetDB()
+		mg := migration.NewMigrator(db, []any{}, apimigration.MigrationList)
 
-	mg := migration.NewMigrator(db, []any{}, apimigration.MigrationList)
+		s.NoError(mg.Upgrade())
+	})
+}
+
+// note and tag are the fixture models of TestMigratorSteps, noteWithColor is the note after its column was added.
+type note struct {
+	ID   int
+	Text string
+}
+
+type noteWithColor struct {
+	ID    int
+	Text  string
+	Color string
+}
+
+func (noteWithColor) TableName() string {
+	return "notes"
+}
+
+type tag struct {
+	ID   int
+	Name string
+}
+
+// stepMigrations creates two tables, then adds a column, which SQLite cannot plan to drop without running it.
+var stepMigrations = []*gormigrate.Migration{{
+	ID: "create-notes",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&note{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&note{})
+	},
+}, {
+	ID: "create-tags",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&tag{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&tag{})
+	},
+}, {
+	ID: "add-note-color",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&noteWithColor{}, "Color")
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&noteWithColor{}, "Color")
+	},
+}}
+
+func TestMigratorSteps(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+
+		mg := migration.NewMigrator(db, []any{}, stepMigrations)
+
+		plans, err := mg.DryRunMigrateTo("create-notes")
+		s.NoError(err)
+		s.Len(plans, 1)
+		s.Equal("create-notes", plans[0].ID)
+		s.NotEmpty(plans[0].SQL)
+
+		statuses, err := mg.Status()
+		s.NoError(err)
+		s.Len(statuses, len(stepMigrations))
+		for _, status := range statuses {
+			s.False(status.Applied, status.ID)
+		}
+
+		s.NoError(mg.MigrateTo("create-notes"))
+		statuses, err = mg.Status()
+		s.NoError(err)
+		s.True(statuses[0].Applied)
+		s.False(statuses[1].Applied)
+
+		plans, err = mg.DryRunMigrateTo("")
+		s.NoError(err)
+		s.Len(plans, 2)
+
+		s.NoError(mg.MigrateTo("create-tags"))
+		plans, err = mg.DryRunRollbackTo("create-notes")
+		s.NoError(err)
+		s.Len(plans, 1)
+		s.Equal("create-tags", plans[0].ID)
+		s.NotEmpty(plans[0].SQL)
+
+		s.NoError(mg.Upgrade())
+		_, err = mg.DryRunRollbackTo("create-notes")
+		if db.Dialector.Name() == "sqlite" {
+			// SQLite drops a column by recreating the table, which needs to read the schema.
+			s.Error(err)
+		} else {
+			s.NoError(err)
+		}
+
+		s.NoError(mg.RollbackTo("create-tags"))
+		statuses, err = mg.Status()
+		s.NoError(err)
+		s.True(statuses[1].Applied)
+		s.False(statuses[2].Applied)
+		s.False(db.Migrator().HasColumn(&noteWithColor{}, "Color"))
 
-	s.NoError(mg.Upgrade())
+		s.Error(mg.MigrateTo("unknown"))
+	})
 }
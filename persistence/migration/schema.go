@@ -0,0 +1,226 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+)
+
+// Column is the definition of a column as read back from the database.
+type Column struct {
+	Type       string
+	Nullable   bool
+	PrimaryKey bool
+	Default    string
+}
+
+func (c Column) String() string {
+	s := c.Type
+	if c.PrimaryKey {
+		s += " primary key"
+	}
+	if !c.Nullable {
+		s += " not null"
+	}
+	if c.Default != "" {
+		s += " default " + c.Default
+	}
+	return s
+}
+
+// Index is the definition of an index as read back from the database.
+type Index struct {
+	Columns []string
+	Unique  bool
+}
+
+func (i Index) String() string {
+	s := "(" + strings.Join(i.Columns, ", ") + ")"
+	if i.Unique {
+		s = "unique " + s
+	}
+	return s
+}
+
+// Table is the definition of a table as read back from the database.
+type Table struct {
+	Columns map[string]Column
+	Indexes map[string]Index
+}
+
+// Schema is the definition of the tables in a database by name.
+type Schema map[string]Table
+
+// ReadSchema reads the tables of the database, except the table of gormigrate and the internal tables of SQLite.
+func ReadSchema(db *gorm.DB) (Schema, error) {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %v", err)
+	}
+
+	schema := make(Schema, len(tables))
+	for _, name := range tables {
+		if name == gormigrate.DefaultOptions.TableName || strings.HasPrefix(name, "sqlite_") {
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %v", name, err)
+		}
+
+		table := Table{Columns: make(map[string]Column), Indexes: make(map[string]Index)}
+		for _, columnType := range columnTypes {
+			column := Column{Type: strings.ToLower(columnType.DatabaseTypeName())}
+			column.Nullable, _ = columnType.Nullable()
+			column.PrimaryKey, _ = columnType.PrimaryKey()
+			column.Default, _ = columnType.DefaultValue()
+			table.Columns[columnType.Name()] = column
+		}
+
+		indexes, err := db.Migrator().GetIndexes(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %v", name, err)
+		}
+		for _, index := range indexes {
+			if primaryKey, _ := index.PrimaryKey(); primaryKey {
+				continue
+			}
+			unique, _ := index.Unique()
+			table.Indexes[index.Name()] = Index{Columns: index.Columns(), Unique: unique}
+		}
+
+		schema[name] = table
+	}
+
+	return schema, nil
+}
+
+// Diff returns the differences of the got schema from the want schema, one readable line each.
+func Diff(want, got Schema) []string {
+	var diff []string
+	for _, name := range sortedKeys(want, got) {
+		wantTable, inWant := want[name]
+		gotTable, inGot := got[name]
+		switch {
+		case !inGot:
+			diff = append(diff, fmt.Sprintf("- table %s", name))
+			continue
+		case !inWant:
+			diff = append(diff, fmt.Sprintf("+ table %s", name))
+			continue
+		}
+
+		diff = append(diff, diffDefinitions("column", name, wantTable.Columns, gotTable.Columns)...)
+		diff = append(diff, diffDefinitions("index", name, wantTable.Indexes, gotTable.Indexes)...)
+	}
+
+	return diff
+}
+
+func diffDefinitions[V fmt.Stringer](kind, table string, want, got map[string]V) []string {
+	var diff []string
+	for _, name := range sortedKeys(want, got) {
+		wantDefinition, inWant := want[name]
+		gotDefinition, inGot := got[name]
+		switch {
+		case !inGot:
+			diff = append(diff, fmt.Sprintf("- %s %s.%s %s", kind, table, name, wantDefinition))
+		case !inWant:
+			diff = append(diff, fmt.Sprintf("+ %s %s.%s %s", kind, table, name, gotDefinition))
+		case wantDefinition.String() != gotDefinition.String():
+			diff = append(diff, fmt.Sprintf("~ %s %s.%s %s -> %s", kind, table, name, wantDefinition, gotDefinition))
+		}
+	}
+
+	return diff
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// Verify applies the migrations and autoMigrate to two scratch databases of the same dialect as db,
+// and returns the differences of the migrated schema from the auto migrated one. Any difference means
+// the models and the migrations diverged.
+func Verify(ctx context.Context, db *gorm.DB, migrations []*gormigrate.Migration, autoMigrate func(*gorm.DB) error) ([]string, error) {
+	var want, got Schema
+	err := withScratchDB(ctx, db, "models", func(scratch *gorm.DB) error {
+		if err := autoMigrate(scratch); err != nil {
+			return fmt.Errorf("failed to auto migrate: %v", err)
+		}
+
+		var err error
+		want, err = ReadSchema(scratch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = withScratchDB(ctx, db, "migrations", func(scratch *gorm.DB) error {
+		if err := NewMigrator(scratch, []any{}, migrations).Upgrade(); err != nil {
+			return fmt.Errorf("failed to migrate: %v", err)
+		}
+
+		var err error
+		got, err = ReadSchema(scratch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return Diff(want, got), nil
+}
+
+// withScratchDB calls fn with an empty database, which is dropped afterwards. SQLite uses a private
+// in-memory database, Postgres a temporary schema on a dedicated connection.
+func withScratchDB(ctx context.Context, db *gorm.DB, name string, fn func(*gorm.DB) error) error {
+	name = fmt.Sprintf("cerberus_verify_%s_%d", name, time.Now().UnixNano())
+
+	if _, ok := database.SQLiteDSN(db.Dialector); ok {
+		scratch, err := gorm.Open(database.SQLiteDialector("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			return fmt.Errorf("failed to open scratch database: %v", err)
+		}
+		sqlDB, err := scratch.DB()
+		if err != nil {
+			return fmt.Errorf("failed to open scratch database: %v", err)
+		}
+		defer sqlDB.Close()
+
+		return fn(scratch.WithContext(ctx))
+	}
+
+	return db.WithContext(ctx).Session(&gorm.Session{Logger: logger.Discard}).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("CREATE SCHEMA " + name).Error; err != nil {
+			return fmt.Errorf("failed to create scratch schema: %v", err)
+		}
+		defer conn.Exec("DROP SCHEMA " + name + " CASCADE")
+
+		if err := conn.Exec("SET search_path TO " + name).Error; err != nil {
+			return fmt.Errorf("failed to use scratch schema: %v", err)
+		}
+		defer conn.Exec("RESET search_path")
+
+		return fn(conn)
+	})
+}
@@ -0,0 +1,42 @@
+package migration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/migration"
+)
+
+func TestDiff(t *testing.T) {
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+
+		type Note struct {
+			ID     int
+			Text   string `gorm:"not null"`
+			Author string `gorm:"index"`
+		}
+		s.NoError(db.AutoMigrate(&Note{}))
+		want, err := migration.ReadSchema(db)
+		s.NoError(err)
+		s.Contains(want, "notes")
+		s.Equal("text not null", want["notes"].Columns["text"].String())
+		s.Empty(migration.Diff(want, want))
+
+		s.NoError(db.Migrator().DropIndex(&Note{}, "Author"))
+		got, err := migration.ReadSchema(db)
+		s.NoError(err)
+		got["notes"].Columns["text"] = migration.Column{Type: "text", Nullable: true}
+		got["tags"] = migration.Table{}
+		s.Equal([]string{
+			"~ column notes.text text not null -> text",
+			"- index notes.idx_notes_author (author)",
+			"+ table tags",
+		}, migration.Diff(want, got))
+
+		s.NoError(db.Migrator().DropTable(&Note{}))
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.AIUsageRepository = (*GORMRepository)(nil)
+
+// AIUsage represents the tokens used by an AI service call.
+type AIUsage struct {
+	ID               int       `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index:idx_ai_usage_team_id_created_at,priority:2;index:idx_ai_usage_user_id_created_at,priority:2"`
+	TeamID           string    `gorm:"type:text;not null;default:'';index:idx_ai_usage_team_id_created_at,priority:1"`
+	UserID           string    `gorm:"type:text;not null;default:'';index:idx_ai_usage_user_id_created_at,priority:1"`
+	Provider         string    `gorm:"type:text;not null"`
+	Model            string    `gorm:"type:text;not null"`
+	Method           string    `gorm:"type:text;not null"`
+	PromptTokens     int       `gorm:"type:integer;not null"`
+	CompletionTokens int       `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (u AIUsage) TableName() string {
+	return "ai_usage"
+}
+
+// CreateAIUsage records the tokens used by an AI service call.
+func (r *GORMRepository) CreateAIUsage(ctx context.Context, req domain.CreateAIUsageRequest) error {
+	usage := AIUsage{
+		TeamID:           req.TeamID,
+		UserID:           req.UserID,
+		Provider:         req.Provider,
+		Model:            req.Model,
+		Method:           req.Method,
+		PromptTokens:     req.PromptTokens,
+		CompletionTokens: req.CompletionTokens,
+	}
+
+	if err := r.conn(ctx).Create(&usage).Error; err != nil {
+		return fmt.Errorf("failed to create ai usage: %v", err)
+	}
+
+	return nil
+}
+
+// SumAIUsage sums the usage up by user and model, ordered by user and model.
+func (r *GORMRepository) SumAIUsage(ctx context.Context, req domain.SumAIUsageRequest) ([]domain.AIUsageSummary, error) {
+	query := r.conn(ctx).Model(&AIUsage{})
+	if req.TeamID != "" {
+		query = query.Where("team_id = ?", req.TeamID)
+	}
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Since != nil {
+		query = query.Where("created_at >= ?", *req.Since)
+	}
+	if req.Until != nil {
+		query = query.Where("created_at < ?", *req.Until)
+	}
+
+	var summaries []domain.AIUsageSummary
+	if err := query.Select("user_id, model, COUNT(*) AS calls, " +
+		"SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens").
+		Group("user_id, model").Order("user_id, model").
+		Scan(&summaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum ai usage: %v", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestSumAIUsage(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		for _, req := range []domain.CreateAIUsageRequest{
+			{TeamID: "T1", UserID: "U1", Model: "m1", PromptTokens: 10, CompletionTokens: 1},
+			{TeamID: "T1", UserID: "U1", Model: "m1", PromptTokens: 20, CompletionTokens: 2},
+			{TeamID: "T1", UserID: "U1", Model: "m2", PromptTokens: 5, CompletionTokens: 5},
+			{TeamID: "T1", UserID: "U2", Model: "m1", PromptTokens: 1, CompletionTokens: 1},
+			{TeamID: "T2", UserID: "U3", Model: "m1", PromptTokens: 100, CompletionTokens: 100},
+		} {
+			req.Provider, req.Method = "gemini", "GetEmotionScore"
+			s.NoError(repo.CreateAIUsage(ctx, req))
+		}
+
+		summaries, err := repo.SumAIUsage(ctx, domain.SumAIUsageRequest{TeamID: "T1"})
+		s.NoError(err)
+		s.Equal([]domain.AIUsageSummary{
+			{UserID: "U1", Model: "m1", Calls: 2, PromptTokens: 30, CompletionTokens: 3},
+			{UserID: "U1", Model: "m2", Calls: 1, PromptTokens: 5, CompletionTokens: 5},
+			{UserID: "U2", Model: "m1", Calls: 1, PromptTokens: 1, CompletionTokens: 1},
+		}, summaries)
+
+		tomorrow := time.Now().Add(24 * time.Hour)
+		summaries, err = repo.SumAIUsage(ctx, domain.SumAIUsageRequest{UserID: "U1", Since: &tomorrow})
+		s.NoError(err)
+		s.Empty(summaries)
+	})
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.AuditLogRepository = (*GORMRepository)(nil)
+
+// AuditLog represents an audit trail entry.
+type AuditLog struct {
+	ID        int `gorm:"primaryKey"`
+	CreatedAt time.Time
+	Action    string `gorm:"type:text;not null;index:idx_audit_log_action"`
+	UserID    string `gorm:"type:text;not null;default:''"`
+	Detail    string `gorm:"type:text;not null;default:''"`
+}
+
+// TableName returns the table name.
+func (a AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// CreateAuditLog creates a new audit log.
+func (r *GORMRepository) CreateAuditLog(ctx context.Context, req domain.CreateAuditLogRequest) (int, error) {
+	auditLog := AuditLog{
+		Action: string(req.Action),
+		UserID: req.UserID,
+		Detail: req.Detail,
+	}
+
+	if err := r.conn(ctx).Create(&auditLog).Error; err != nil {
+		return 0, fmt.Errorf("failed to create audit log: %v", err)
+	}
+
+	return auditLog.ID, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.BuddyRepository = (*GORMRepository)(nil)
+
+// Buddy represents a user who gets notified when another user's mood stays low.
+type Buddy struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         string `gorm:"type:text;not null;uniqueIndex:idx_buddy_user_id"`
+	BuddyUserID    string `gorm:"type:text;not null;index:idx_buddy_buddy_user_id"`
+	Status         string `gorm:"type:text;not null"`
+	Threshold      int    `gorm:"type:integer;not null"`
+	Days           int    `gorm:"type:integer;not null"`
+	ConfirmedAt    *time.Time
+	LastNotifiedAt *time.Time
+}
+
+// TableName returns the table name.
+func (b Buddy) TableName() string {
+	return "buddies"
+}
+
+func (b Buddy) toDomain() domain.Buddy {
+	return domain.Buddy{
+		ID:             b.ID,
+		CreatedAt:      b.CreatedAt,
+		UpdatedAt:      b.UpdatedAt,
+		UserID:         b.UserID,
+		BuddyUserID:    b.BuddyUserID,
+		Status:         domain.BuddyStatus(b.Status),
+		Threshold:      b.Threshold,
+		Days:           b.Days,
+		ConfirmedAt:    b.ConfirmedAt,
+		LastNotifiedAt: b.LastNotifiedAt,
+	}
+}
+
+// CreateBuddy creates a new pending buddy, replacing any existing buddy of the user.
+func (r *GORMRepository) CreateBuddy(ctx context.Context, req domain.CreateBuddyRequest) (int, error) {
+	buddy := Buddy{
+		UserID:      req.UserID,
+		BuddyUserID: req.BuddyUserID,
+		Status:      string(domain.BuddyStatusPending),
+		Threshold:   req.Threshold,
+		Days:        req.Days,
+	}
+
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", req.UserID).Delete(&Buddy{}).Error; err != nil {
+			return fmt.Errorf("failed to delete existing buddy: %v", err)
+		}
+
+		if err := tx.Create(&buddy).Error; err != nil {
+			return fmt.Errorf("failed to create buddy: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return buddy.ID, nil
+}
+
+// GetBuddy gets a buddy by id.
+func (r *GORMRepository) GetBuddy(ctx context.Context, id int) (*domain.Buddy, error) {
+	buddy := Buddy{}
+	err := r.conn(ctx).First(&buddy, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to find buddy: %v", err)
+	}
+
+	result := buddy.toDomain()
+	return &result, nil
+}
+
+// UpdateBuddy updates a buddy.
+func (r *GORMRepository) UpdateBuddy(ctx context.Context, id int, req domain.UpdateBuddyRequest) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		buddy := Buddy{}
+		if err := tx.First(&buddy, id).Error; err != nil {
+			return fmt.Errorf("failed to find buddy: %v", err)
+		}
+
+		if req.Status != nil {
+			buddy.Status = string(*req.Status)
+		}
+		if req.ConfirmedAt != nil {
+			buddy.ConfirmedAt = req.ConfirmedAt
+		}
+		if req.LastNotifiedAt != nil {
+			buddy.LastNotifiedAt = req.LastNotifiedAt
+		}
+		return tx.Save(&buddy).Error
+	})
+}
+
+// DeleteBuddyByUserID deletes the buddy of the user.
+func (r *GORMRepository) DeleteBuddyByUserID(ctx context.Context, userID string) error {
+	if err := r.conn(ctx).Where("user_id = ?", userID).Delete(&Buddy{}).Error; err != nil {
+		return fmt.Errorf("failed to delete buddy: %v", err)
+	}
+
+	return nil
+}
+
+// ListBuddies lists buddies.
+func (r *GORMRepository) ListBuddies(ctx context.Context, req domain.ListBuddiesRequest) ([]domain.Buddy, error) {
+	query := r.conn(ctx)
+	if req.Status != nil {
+		query = query.Where("status = ?", string(*req.Status))
+	}
+
+	var buddies []Buddy
+	if err := query.Order("id").Find(&buddies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list buddies: %v", err)
+	}
+
+	result := make([]domain.Buddy, 0, len(buddies))
+	for _, buddy := range buddies {
+		result = append(result, buddy.toDomain())
+	}
+
+	return result, nil
+}
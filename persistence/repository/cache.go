@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/omegaatt36/cerberus/pkg/cache"
+)
+
+var _ cache.Store = (*GORMRepository)(nil)
+
+// CacheEntry represents a cached value.
+type CacheEntry struct {
+	CacheKey  string    `gorm:"type:text;primaryKey"`
+	Value     string    `gorm:"type:text;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the table name.
+func (e CacheEntry) TableName() string {
+	return "cache_entries"
+}
+
+// GetCacheEntry returns the cached value of the key unless it expired.
+func (r *GORMRepository) GetCacheEntry(ctx context.Context, key string) (string, bool, error) {
+	entry := CacheEntry{}
+	err := r.conn(ctx).Where("cache_key = ? AND expires_at > ?", key, time.Now().UTC()).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("failed to find cache entry: %v", err)
+	}
+
+	return entry.Value, true, nil
+}
+
+// SetCacheEntry sets the cached value of the key.
+func (r *GORMRepository) SetCacheEntry(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := r.conn(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at"}),
+	}).Create(&CacheEntry{
+		CacheKey:  key,
+		Value:     value,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to set cache entry: %v", err)
+	}
+
+	return nil
+}
+
+// PruneCacheEntries deletes the cache entries which expired before now, and returns the number of deleted entries.
+func (r *GORMRepository) PruneCacheEntries(ctx context.Context, now time.Time) (int64, error) {
+	result := r.conn(ctx).Where("expires_at <= ?", now.UTC()).Delete(&CacheEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune cache entries: %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
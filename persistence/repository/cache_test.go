@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestCacheEntry(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		_, ok, err := repo.GetCacheEntry(ctx, "a")
+		s.NoError(err)
+		s.False(ok)
+
+		s.NoError(repo.SetCacheEntry(ctx, "a", "1", time.Hour))
+		s.NoError(repo.SetCacheEntry(ctx, "a", "2", time.Hour))
+		s.NoError(repo.SetCacheEntry(ctx, "b", "3", -time.Hour))
+
+		value, ok, err := repo.GetCacheEntry(ctx, "a")
+		s.NoError(err)
+		s.True(ok)
+		s.Equal("2", value)
+
+		_, ok, err = repo.GetCacheEntry(ctx, "b")
+		s.NoError(err)
+		s.False(ok)
+
+		pruned, err := repo.PruneCacheEntries(ctx, time.Now())
+		s.NoError(err)
+		s.EqualValues(1, pruned)
+	})
+}
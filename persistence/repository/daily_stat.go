@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+// dateLayout is the layout of EmotionDailyStat.Date.
+const dateLayout = "2006-01-02"
+
+// EmotionDailyStat represents the aggregated emotions of a user in a channel on a day.
+type EmotionDailyStat struct {
+	ID             int `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TeamID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:1;index:idx_emotion_daily_stat_user,priority:1"`
+	ChannelID      string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:2"`
+	UserID         string  `gorm:"type:text;not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:3;index:idx_emotion_daily_stat_user,priority:2"`
+	Date           string  `gorm:"type:varchar(10);not null;uniqueIndex:idx_emotion_daily_stat_bucket,priority:4;index:idx_emotion_daily_stat_user,priority:3"`
+	Count          int     `gorm:"type:integer;not null"`
+	ScoredCount    int     `gorm:"type:integer;not null;default:0"`
+	AvgScore       float64 `gorm:"not null"`
+	MinScore       int     `gorm:"type:integer;not null"`
+	MaxScore       int     `gorm:"type:integer;not null"`
+	TasksCompleted int     `gorm:"type:integer;not null"`
+}
+
+// TableName returns the table name.
+func (s EmotionDailyStat) TableName() string {
+	return "emotion_daily_stats"
+}
+
+// dailyStatBucket identifies a row of EmotionDailyStat, TimeZone decides where the day starts and ends.
+type dailyStatBucket struct {
+	TeamID    string
+	ChannelID string
+	UserID    string
+	Date      string
+	TimeZone  string
+}
+
+// location loads the IANA time zone, unknown or empty time zones fall back to UTC.
+func location(timeZone string) *time.Location {
+	if timeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// bucketOf returns the bucket which the emotion is aggregated into, the date is in the time zone of the user.
+func bucketOf(e Emotion) dailyStatBucket {
+	return dailyStatBucket{
+		TeamID:    e.TeamID,
+		ChannelID: e.ChannelID,
+		UserID:    e.UserID,
+		Date:      e.CreatedAt.In(location(e.TimeZone)).Format(dateLayout),
+		TimeZone:  e.TimeZone,
+	}
+}
+
+// bucketsOf returns the set of buckets which the emotions are aggregated into.
+func bucketsOf(emotions ...Emotion) map[dailyStatBucket]struct{} {
+	buckets := make(map[dailyStatBucket]struct{}, len(emotions))
+	for _, emotion := range emotions {
+		buckets[bucketOf(emotion)] = struct{}{}
+	}
+
+	return buckets
+}
+
+// refreshDailyStats recomputes the buckets from the emotions which are not deleted, the scores only from
+// the scored ones. Anonymized emotions have no user and are never aggregated.
+func refreshDailyStats(tx *gorm.DB, buckets map[dailyStatBucket]struct{}) error {
+	for bucket := range buckets {
+		if bucket.UserID == "" {
+			continue
+		}
+
+		start, err := time.ParseInLocation(dateLayout, bucket.Date, location(bucket.TimeZone))
+		if err != nil {
+			return fmt.Errorf("invalid bucket date %q: %v", bucket.Date, err)
+		}
+
+		var stat EmotionDailyStat
+		if err := tx.Model(&Emotion{}).
+			Select("COUNT(*) AS count, COUNT(score) AS scored_count, COALESCE(AVG(score), 0) AS avg_score, "+
+				"COALESCE(MIN(score), 0) AS min_score, COALESCE(MAX(score), 0) AS max_score, "+
+				"COUNT(task_completed_at) AS tasks_completed").
+			Where("team_id = ? AND channel_id = ? AND user_id = ?", bucket.TeamID, bucket.ChannelID, bucket.UserID).
+			Where("created_at >= ? AND created_at < ?", start.UTC(), start.AddDate(0, 0, 1).UTC()).
+			Scan(&stat).Error; err != nil {
+			return fmt.Errorf("failed to aggregate emotions: %v", err)
+		}
+
+		where := tx.Where("team_id = ? AND channel_id = ? AND user_id = ? AND date = ?",
+			bucket.TeamID, bucket.ChannelID, bucket.UserID, bucket.Date)
+		if stat.Count == 0 {
+			if err := where.Delete(&EmotionDailyStat{}).Error; err != nil {
+				return fmt.Errorf("failed to delete daily stat: %v", err)
+			}
+			continue
+		}
+
+		stat.TeamID, stat.ChannelID, stat.UserID, stat.Date = bucket.TeamID, bucket.ChannelID, bucket.UserID, bucket.Date
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "team_id"}, {Name: "channel_id"}, {Name: "user_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"updated_at", "count", "scored_count", "avg_score", "min_score", "max_score", "tasks_completed",
+			}),
+		}).Create(&stat).Error; err != nil {
+			return fmt.Errorf("failed to upsert daily stat: %v", err)
+		}
+	}
+
+	return nil
+}
+
+var _ domain.DailyStatRepository = (*GORMRepository)(nil)
+
+// dailyStatColumns aggregates the buckets of a day, the scores of the buckets with scored emotions only,
+// and the average weighted by their number.
+const dailyStatColumns = "date, SUM(count) AS count, SUM(scored_count) AS scored_count, " +
+	"COALESCE(SUM(avg_score * scored_count) / NULLIF(SUM(scored_count), 0), 0) AS avg_score, " +
+	"COALESCE(MIN(CASE WHEN scored_count > 0 THEN min_score END), 0) AS min_score, " +
+	"COALESCE(MAX(CASE WHEN scored_count > 0 THEN max_score END), 0) AS max_score, " +
+	"SUM(tasks_completed) AS tasks_completed"
+
+// listDailyStats lists the daily statistics in the date range aggregated by date.
+func (r *GORMRepository) listDailyStats(ctx context.Context, from, to time.Time, scope func(*gorm.DB) *gorm.DB) ([]domain.DailyStat, error) {
+	var stats []EmotionDailyStat
+	if err := r.conn(ctx).Model(&EmotionDailyStat{}).Scopes(scope).
+		Select(dailyStatColumns).
+		Where("date >= ? AND date <= ?", from.Format(dateLayout), to.Format(dateLayout)).
+		Group("date").Order("date").
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to list daily stats: %v", err)
+	}
+
+	result := make([]domain.DailyStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, domain.DailyStat{
+			Date:           stat.Date,
+			Count:          stat.Count,
+			ScoredCount:    stat.ScoredCount,
+			AvgScore:       stat.AvgScore,
+			MinScore:       stat.MinScore,
+			MaxScore:       stat.MaxScore,
+			TasksCompleted: stat.TasksCompleted,
+		})
+	}
+
+	return result, nil
+}
+
+// ListUserDailyStats lists the daily statistics of the user across channels in date order.
+func (r *GORMRepository) ListUserDailyStats(ctx context.Context, req domain.ListUserDailyStatsRequest) ([]domain.DailyStat, error) {
+	return r.listDailyStats(ctx, req.From, req.To, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("team_id = ? AND user_id = ?", req.TeamID, req.UserID)
+	})
+}
+
+// ListChannelDailyStats lists the daily statistics of the channel across users in date order.
+func (r *GORMRepository) ListChannelDailyStats(ctx context.Context, req domain.ListChannelDailyStatsRequest) ([]domain.DailyStat, error) {
+	return r.listDailyStats(ctx, req.From, req.To, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("team_id = ? AND channel_id = ?", req.TeamID, req.ChannelID)
+	})
+}
+
+// RebuildDailyStats recomputes every bucket which has emotions, including soft deleted ones so their
+// buckets are cleaned up. Buckets whose emotions were pruned by the retention are kept as they are.
+func (r *GORMRepository) RebuildDailyStats(ctx context.Context) (int, error) {
+	var count int
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var emotions []Emotion
+		if err := tx.Unscoped().Select("team_id", "channel_id", "user_id", "time_zone", "created_at").
+			Where("user_id <> ''").Find(&emotions).Error; err != nil {
+			return fmt.Errorf("failed to list emotions: %v", err)
+		}
+
+		buckets := bucketsOf(emotions...)
+		count = len(buckets)
+		return refreshDailyStats(tx, buckets)
+	})
+
+	return count, err
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestDailyStats(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		create := func(channelID, userID string, score int) int {
+			id, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{
+				TeamID: "T1", ChannelID: channelID, UserID: userID, TimeZone: "Asia/Taipei", Emoji: ":smile:",
+			})
+			s.NoError(err)
+			s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Score: &score}))
+			return id
+		}
+		create("C1", "U1", 20)
+		create("C2", "U1", 60)
+		create("C1", "U2", 70)
+		deleted := create("C1", "U2", 10)
+		s.NoError(repo.DeleteEmotion(ctx, deleted, "U2"))
+		// Unscored check-ins are counted, but don't change the scores.
+		_, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{
+			TeamID: "T1", ChannelID: "C3", UserID: "U1", TimeZone: "Asia/Taipei", Emoji: ":smile:",
+		})
+		s.NoError(err)
+
+		taipei, err := time.LoadLocation("Asia/Taipei")
+		s.NoError(err)
+		today := time.Now().In(taipei)
+
+		stats, err := repo.ListUserDailyStats(ctx, domain.ListUserDailyStatsRequest{
+			TeamID: "T1", UserID: "U1", From: today.AddDate(0, 0, -7), To: today,
+		})
+		s.NoError(err)
+		s.Equal([]domain.DailyStat{{
+			Date: today.Format("2006-01-02"), Count: 3, ScoredCount: 2, AvgScore: 40, MinScore: 20, MaxScore: 60,
+		}}, stats)
+
+		stats, err = repo.ListChannelDailyStats(ctx, domain.ListChannelDailyStatsRequest{
+			TeamID: "T1", ChannelID: "C1", From: today, To: today,
+		})
+		s.NoError(err)
+		s.Len(stats, 1)
+		s.Equal(2, stats[0].Count)
+		s.InDelta(45, stats[0].AvgScore, 0.001)
+
+		// A check-in at 23:30 UTC belongs to the next day in Taipei.
+		lateNight := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+		s.NoError(db.Model(&repository.Emotion{}).Where("user_id = ?", "U1").Update("created_at", lateNight).Error)
+		s.NoError(db.Where("1 = 1").Delete(&repository.EmotionDailyStat{}).Error)
+
+		count, err := repo.RebuildDailyStats(ctx)
+		s.NoError(err)
+		s.Equal(4, count)
+
+		stats, err = repo.ListUserDailyStats(ctx, domain.ListUserDailyStatsRequest{
+			TeamID: "T1", UserID: "U1", From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		})
+		s.NoError(err)
+		s.Len(stats, 1)
+		s.Equal("2026-03-02", stats[0].Date)
+		s.Equal(3, stats[0].Count)
+		s.Equal(2, stats[0].ScoredCount)
+		s.Equal(20, stats[0].MinScore)
+	})
+}
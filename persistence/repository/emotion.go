@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,15 +15,20 @@ var _ domain.EmotionRepository = (*GORMRepository)(nil)
 
 // Emotion represents a emotion.
 type Emotion struct {
-	ID              int `gorm:"primaryKey"`
-	CreatedAt       time.Time
+	ID              int       `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index:idx_emotion_team_id_created_at,priority:2"`
 	UpdatedAt       time.Time
+	TeamID          string `gorm:"type:text;not null;default:'';index:idx_emotion_team_id_created_at,priority:1"`
+	ChannelID       string `gorm:"type:text;not null;default:''"`
 	UserID          string `gorm:"type:text;not null;index:idx_user_id"`
+	TimeZone        string `gorm:"type:text;not null;default:''"`
 	Emoji           string `gorm:"type:text;not null"`
-	Description     string `gorm:"type:text;not null;default:''"`
-	Score           int    `gorm:"type:integer"`
-	Task            string `gorm:"type:text"`
+	Description     string `gorm:"type:text;not null;default:'';serializer:encrypted"`
+	Score           *int   `gorm:"type:integer"`
+	Task            string `gorm:"type:text;serializer:encrypted"`
 	TaskCompletedAt *time.Time
+	Version         int            `gorm:"type:integer;not null;default:1"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
 }
 
 // TableName returns the table name.
@@ -30,44 +36,236 @@ func (e Emotion) TableName() string {
 	return "emotions"
 }
 
+func (e Emotion) toDomain() domain.Emotion {
+	return domain.Emotion{
+		ID:              e.ID,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+		TeamID:          e.TeamID,
+		ChannelID:       e.ChannelID,
+		UserID:          e.UserID,
+		TimeZone:        e.TimeZone,
+		Emoji:           e.Emoji,
+		Description:     e.Description,
+		Score:           e.Score,
+		Task:            e.Task,
+		TaskCompletedAt: e.TaskCompletedAt,
+		Version:         e.Version,
+	}
+}
+
 // CreateEmotion creates a new emotion.
 func (r *GORMRepository) CreateEmotion(ctx context.Context, req domain.CreateEmotionRequest) (int, error) {
 	emotion := Emotion{
+		TeamID:      req.TeamID,
+		ChannelID:   req.ChannelID,
 		UserID:      req.UserID,
+		TimeZone:    req.TimeZone,
 		Emoji:       req.Emoji,
 		Description: req.Description,
+		Version:     1,
 	}
 
-	if err := r.db.Create(&emotion).Error; err != nil {
-		return 0, fmt.Errorf("failed to create emotion: %v", err)
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&emotion).Error; err != nil {
+			return fmt.Errorf("failed to create emotion: %v", err)
+		}
+
+		return refreshDailyStats(tx, bucketsOf(emotion))
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	return emotion.ID, nil
 }
 
-// UpdateEmotion updates an emotion.
+// UpdateEmotion updates the set fields of an emotion and increments its version, if the version is still
+// req.Version, or the version read in the same transaction when it is not set. It returns
+// domain.ErrConflict otherwise.
 func (r *GORMRepository) UpdateEmotion(ctx context.Context, id int, req domain.UpdateEmotionRequest) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		emotion := Emotion{}
 		if err := tx.First(&emotion, id).Error; err != nil {
 			return fmt.Errorf("failed to find emotion: %v", err)
 		}
 
+		version := emotion.Version
+		if req.Version != nil {
+			version = *req.Version
+		}
+
+		columns := []string{"Version"}
 		if req.Emoji != nil {
 			emotion.Emoji = *req.Emoji
+			columns = append(columns, "Emoji")
 		}
 		if req.Description != nil {
 			emotion.Description = *req.Description
+			columns = append(columns, "Description")
 		}
 		if req.Score != nil {
-			emotion.Score = *req.Score
+			emotion.Score = req.Score
+			columns = append(columns, "Score")
 		}
 		if req.Task != nil {
 			emotion.Task = *req.Task
+			columns = append(columns, "Task")
 		}
 		if req.TaskCompletedAt != nil {
 			emotion.TaskCompletedAt = req.TaskCompletedAt
+			columns = append(columns, "TaskCompletedAt")
+		}
+		emotion.Version = version + 1
+
+		result := tx.Model(&emotion).Where("version = ?", version).Select(columns).Updates(&emotion)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update emotion: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrConflict
 		}
-		return tx.Save(&emotion).Error
+
+		return refreshDailyStats(tx, bucketsOf(emotion))
+	})
+}
+
+// GetLatestEmotion gets the latest emotion of the user.
+func (r *GORMRepository) GetLatestEmotion(ctx context.Context, userID string) (*domain.Emotion, error) {
+	emotion := Emotion{}
+	err := r.conn(ctx).Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&emotion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to find latest emotion: %v", err)
+	}
+
+	result := emotion.toDomain()
+	return &result, nil
+}
+
+// DeleteEmotion soft deletes an emotion owned by the user.
+func (r *GORMRepository) DeleteEmotion(ctx context.Context, id int, userID string) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		emotion := Emotion{}
+		err := tx.First(&emotion, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to find emotion: %v", err)
+		}
+
+		if emotion.UserID != userID {
+			return domain.ErrPermissionDenied
+		}
+
+		if err := tx.Delete(&emotion).Error; err != nil {
+			return fmt.Errorf("failed to delete emotion: %v", err)
+		}
+
+		return refreshDailyStats(tx, bucketsOf(emotion))
 	})
 }
+
+// ListEmotions lists emotions in created order.
+func (r *GORMRepository) ListEmotions(ctx context.Context, req domain.ListEmotionsRequest) ([]domain.Emotion, error) {
+	query := r.conn(ctx)
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Since != nil {
+		query = query.Where("created_at >= ?", *req.Since)
+	}
+	if req.Until != nil {
+		query = query.Where("created_at < ?", *req.Until)
+	}
+	if req.After != nil {
+		// The creation time as it was read, so it compares equal to the stored one.
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))",
+			req.After.CreatedAt, req.After.CreatedAt, req.After.ID)
+	}
+	if req.Limit > 0 {
+		query = query.Limit(req.Limit)
+	}
+
+	var emotions []Emotion
+	if err := query.Order("created_at, id").Find(&emotions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list emotions: %v", err)
+	}
+
+	result := make([]domain.Emotion, 0, len(emotions))
+	for _, emotion := range emotions {
+		result = append(result, emotion.toDomain())
+	}
+
+	return result, nil
+}
+
+// ReencryptEmotions encrypts the personal content of every emotion, including soft deleted ones,
+// which is stored in plain text, under a key other than the active key or in an older format. It returns
+// the number of re-encrypted emotions.
+func (r *GORMRepository) ReencryptEmotions(ctx context.Context, batchSize int) (int, error) {
+	k := r.keyring
+	if k == nil {
+		return 0, fmt.Errorf("no encryption key is configured")
+	}
+
+	var count, lastID int
+	for {
+		// Read the raw column values without the serializer.
+		var rows []struct {
+			ID          int
+			Description string
+			Task        string
+		}
+		if err := r.conn(ctx).Table(Emotion{}.TableName()).
+			Select("id", "description", "COALESCE(task, '') AS task").
+			Where("id > ?", lastID).Order("id").Limit(batchSize).
+			Scan(&rows).Error; err != nil {
+			return count, fmt.Errorf("failed to list emotions: %v", err)
+		}
+
+		if len(rows) == 0 {
+			return count, nil
+		}
+
+		for _, row := range rows {
+			lastID = row.ID
+			if k.IsCurrent(row.Description) && k.IsCurrent(row.Task) {
+				continue
+			}
+
+			reencrypt := func() error {
+				return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+					emotion := Emotion{}
+					if err := tx.Unscoped().First(&emotion, row.ID).Error; err != nil {
+						return fmt.Errorf("failed to find emotion: %v", err)
+					}
+
+					// The content is unchanged, so the version is kept, but it must not overwrite an update.
+					update := Emotion{Description: emotion.Description, Task: emotion.Task}
+					result := tx.Unscoped().Model(&emotion).Where("version = ?", emotion.Version).
+						Select("Description", "Task").UpdateColumns(&update)
+					if result.Error != nil {
+						return result.Error
+					}
+					if result.RowsAffected == 0 {
+						return domain.ErrConflict
+					}
+
+					return nil
+				})
+			}
+
+			err := reencrypt()
+			if errors.Is(err, domain.ErrConflict) {
+				// The emotion was updated since it was read, read it again.
+				err = reencrypt()
+			}
+			if err != nil {
+				return count, fmt.Errorf("failed to re-encrypt emotion %d: %w", row.ID, err)
+			}
+			count++
+		}
+	}
+}
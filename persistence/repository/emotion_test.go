@@ -0,0 +1,232 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestEmotionCRUD(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		_, err := repo.GetLatestEmotion(ctx, "U1")
+		s.ErrorIs(err, domain.ErrNotFound)
+
+		firstID, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{TeamID: "T1", UserID: "U1", Emoji: ":cry:"})
+		s.NoError(err)
+		secondID, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{TeamID: "T1", UserID: "U1", Emoji: ":smile:", Description: "lunch"})
+		s.NoError(err)
+		_, err = repo.CreateEmotion(ctx, domain.CreateEmotionRequest{TeamID: "T1", UserID: "U2", Emoji: ":tada:"})
+		s.NoError(err)
+
+		score := 80
+		s.NoError(repo.UpdateEmotion(ctx, secondID, domain.UpdateEmotionRequest{Score: &score}))
+
+		latest, err := repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(secondID, latest.ID)
+		s.Equal("lunch", latest.Description)
+		s.Equal(&score, latest.Score)
+
+		emotions, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 2)
+		s.Equal(firstID, emotions[0].ID)
+
+		s.ErrorIs(repo.DeleteEmotion(ctx, secondID, "U2"), domain.ErrPermissionDenied)
+		s.NoError(repo.DeleteEmotion(ctx, secondID, "U1"))
+		s.ErrorIs(repo.DeleteEmotion(ctx, secondID, "U1"), domain.ErrNotFound)
+
+		latest, err = repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(firstID, latest.ID)
+	})
+}
+
+func TestUpdateEmotionVersion(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		id, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":smile:", Description: "lunch"})
+		s.NoError(err)
+		read, err := repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(1, read.Version)
+
+		// Updates of other fields keep the fields which are not set.
+		score, completedAt := 80, time.Now().UTC()
+		s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Score: &score}))
+		s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{TaskCompletedAt: &completedAt}))
+
+		// An update of the version read before fails.
+		emoji := ":cry:"
+		s.ErrorIs(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Emoji: &emoji, Version: &read.Version}), domain.ErrConflict)
+
+		latest, err := repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(3, latest.Version)
+		s.Equal(":smile:", latest.Emoji)
+		s.Equal("lunch", latest.Description)
+		s.Equal(&score, latest.Score)
+		s.NotNil(latest.TaskCompletedAt)
+
+		s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Emoji: &emoji, Version: &latest.Version}))
+		latest, err = repo.GetLatestEmotion(ctx, "U1")
+		s.NoError(err)
+		s.Equal(4, latest.Version)
+		s.Equal(":cry:", latest.Emoji)
+	})
+}
+
+func TestListEmotionsPages(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		// Check-ins created at the same time are ordered by ID, and IDs are not in created order.
+		day := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+		for _, createdAt := range []time.Time{day.Add(time.Hour), day, day, day.Add(time.Hour), day.Add(-time.Hour)} {
+			s.NoError(db.Create(&repository.Emotion{CreatedAt: createdAt, UserID: "U1", Emoji: ":smile:", Version: 1}).Error)
+		}
+
+		all, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(all, 5)
+
+		var paged []domain.Emotion
+		req := domain.ListEmotionsRequest{UserID: "U1", Limit: 2}
+		for {
+			page, err := repo.ListEmotions(ctx, req)
+			s.NoError(err)
+			paged = append(paged, page...)
+			if len(page) < req.Limit {
+				break
+			}
+			req.After = &page[len(page)-1]
+		}
+
+		s.Equal(all, paged)
+	})
+}
+
+// TestEmotionQueryLogRedacted is not parallel, as it replaces the default logger.
+func TestEmotionQueryLogRedacted(t *testing.T) {
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		var buf bytes.Buffer
+		previous := slog.Default()
+		slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+		defer slog.SetDefault(previous)
+
+		repo := repository.NewGORMRepository(db.Session(&gorm.Session{
+			Logger: database.NewSlogLogger(time.Minute).LogMode(logger.Info),
+		}))
+		s.NoError(repo.AutoMigrate())
+
+		id, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":cry:", Description: "feeling terrible"})
+		s.NoError(err)
+		task := "take a walk"
+		s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Task: &task}))
+
+		logged := buf.String()
+		s.Contains(logged, "INSERT INTO")
+		s.Contains(logged, "UPDATE")
+		s.NotContains(logged, "feeling terrible")
+		s.NotContains(logged, "take a walk")
+	})
+}
+
+func TestEmotionEncryption(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		// Rows written before the encryption is enabled stay readable.
+		plainID, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":cry:", Description: "written in plain text"})
+		s.NoError(err)
+
+		keyring, err := encryption.NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+		s.NoError(err)
+		repo = repository.NewGORMRepository(db, &repository.WithKeyringOption{Keyring: keyring})
+
+		task := "take a walk"
+		_, err = repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":smile:", Description: "written encrypted"})
+		s.NoError(err)
+		s.NoError(repo.UpdateEmotion(ctx, plainID, domain.UpdateEmotionRequest{Task: &task}))
+
+		emotions, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 2)
+		s.Equal("written in plain text", emotions[0].Description)
+		s.Equal(task, emotions[0].Task)
+		s.Equal("written encrypted", emotions[1].Description)
+
+		rotated, err := encryption.NewKeyring("k2", map[string][]byte{
+			"k1": bytes.Repeat([]byte{1}, 32),
+			"k2": bytes.Repeat([]byte{2}, 32),
+		})
+		s.NoError(err)
+		repo = repository.NewGORMRepository(db, &repository.WithKeyringOption{Keyring: rotated})
+
+		count, err := repo.ReencryptEmotions(ctx, 1)
+		s.NoError(err)
+		s.Equal(2, count)
+
+		var raw []string
+		s.NoError(db.Table("emotions").Pluck("description", &raw).Error)
+		for _, value := range raw {
+			keyID, ok := encryption.KeyID(value)
+			s.True(ok)
+			s.Equal("k2", keyID)
+		}
+
+		count, err = repo.ReencryptEmotions(ctx, 1)
+		s.NoError(err)
+		s.Zero(count)
+
+		// A repository without the keyring cannot read the encrypted rows.
+		_, err = repository.NewGORMRepository(db).ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.Error(err)
+
+		// The values are bound to their column, a description copied into the task does not decrypt.
+		s.NoError(db.Exec("UPDATE emotions SET task = description WHERE id = ?", plainID).Error)
+		_, err = repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.ErrorContains(err, "failed to decrypt field Task")
+	})
+}
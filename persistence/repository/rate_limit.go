@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+)
+
+var _ ratelimit.Store = (*GORMRepository)(nil)
+
+// RateLimitBucket represents the token bucket of a rate limit key.
+type RateLimitBucket struct {
+	BucketKey string  `gorm:"type:text;primaryKey"`
+	Tokens    float64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName returns the table name.
+func (b RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// TakeToken takes a token from the bucket of the key, the row is locked so replicas share the bucket.
+func (r *GORMRepository) TakeToken(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (bool, time.Duration, error) {
+	var (
+		allowed    bool
+		retryAfter time.Duration
+	)
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		full := ratelimit.NewBucket(limit, now)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&RateLimitBucket{
+			BucketKey: key,
+			Tokens:    full.Tokens,
+			UpdatedAt: full.UpdatedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to create rate limit bucket: %v", err)
+		}
+
+		row := RateLimitBucket{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("bucket_key = ?", key).First(&row).Error; err != nil {
+			return fmt.Errorf("failed to find rate limit bucket: %v", err)
+		}
+
+		var bucket ratelimit.Bucket
+		bucket, allowed, retryAfter = ratelimit.Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt}.Take(limit, now)
+
+		if err := tx.Model(&row).UpdateColumns(map[string]any{
+			"tokens":     bucket.Tokens,
+			"updated_at": bucket.UpdatedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update rate limit bucket: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	return allowed, retryAfter, nil
+}
+
+// PruneRateLimitBuckets deletes the buckets which are full again at now, as they are the same as new
+// buckets, and returns the number of deleted buckets.
+func (r *GORMRepository) PruneRateLimitBuckets(ctx context.Context, limit ratelimit.Limit, now time.Time) (int64, error) {
+	if limit.Rate <= 0 {
+		return 0, nil
+	}
+
+	refill := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	result := r.conn(ctx).Where("updated_at <= ?", now.Add(-refill).UTC()).Delete(&RateLimitBucket{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune rate limit buckets: %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+)
+
+func TestTakeToken(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		limit := ratelimit.Every(time.Minute, 1)
+		now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+		allowed, _, err := repo.TakeToken(ctx, "T1:U1", limit, now)
+		s.NoError(err)
+		s.True(allowed)
+
+		allowed, retryAfter, err := repo.TakeToken(ctx, "T1:U1", limit, now.Add(30*time.Second))
+		s.NoError(err)
+		s.False(allowed)
+		s.Equal(30*time.Second, retryAfter)
+
+		allowed, _, err = repo.TakeToken(ctx, "T1:U1", limit, now.Add(time.Minute))
+		s.NoError(err)
+		s.True(allowed)
+
+		_, _, err = repo.TakeToken(ctx, "T1:U2", limit, now.Add(90*time.Second))
+		s.NoError(err)
+
+		// T1:U1 is full again a minute after its last token, T1:U2 is not yet.
+		pruned, err := repo.PruneRateLimitBuckets(ctx, limit, now.Add(2*time.Minute))
+		s.NoError(err)
+		s.EqualValues(1, pruned)
+
+		var keys []string
+		s.NoError(db.Model(&repository.RateLimitBucket{}).Pluck("bucket_key", &keys).Error)
+		s.Equal([]string{"T1:U2"}, keys)
+	})
+}
@@ -1,20 +1,86 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+)
+
+var _ domain.Transactor = (*GORMRepository)(nil)
 
 // GORMRepository represents a generic gorm repository which implements repository interface.
 type GORMRepository struct {
 	db *gorm.DB
+
+	// keyring encrypts the fields tagged with `serializer:encrypted`, nil stores them as plain text.
+	keyring *encryption.Keyring
+}
+
+// Option defines repository option.
+type Option interface {
+	apply(*GORMRepository)
+}
+
+// WithKeyringOption defines the option to set the keyring which encrypts personal content at rest.
+type WithKeyringOption struct {
+	Keyring *encryption.Keyring
+}
+
+func (o *WithKeyringOption) apply(r *GORMRepository) {
+	r.keyring = o.Keyring
 }
 
 // NewGORMRepository creates a new gorm repository.
-func NewGORMRepository(db *gorm.DB) *GORMRepository {
-	return &GORMRepository{db: db}
+func NewGORMRepository(db *gorm.DB, options ...Option) *GORMRepository {
+	r := &GORMRepository{db: db}
+	for _, option := range options {
+		option.apply(r)
+	}
+
+	return r
 }
 
 // AutoMigrate migrates tables.
 func (r *GORMRepository) AutoMigrate() error {
 	return r.db.AutoMigrate(
 		&Emotion{},
+		&AuditLog{},
+		&Buddy{},
+		&EmotionDailyStat{},
+		&AIUsage{},
+		&RateLimitBucket{},
+		&CacheEntry{},
 	)
 }
+
+type txContextKey struct{}
+
+// WithinTx implements domain.Transactor. Nested calls run in a savepoint of the outer transaction.
+func (r *GORMRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// conn returns the transaction of WithinTx which the context belongs to, or the db outside of it, with
+// the keyring of the repository in the statement context. The reads of contexts from
+// domain.WithReadYourWrites go to the primary, and the statements are logged with the actor.
+func (r *GORMRepository) conn(ctx context.Context) *gorm.DB {
+	ctx = withKeyring(ctx, r.keyring)
+	if domain.ReadYourWritesFromContext(ctx) {
+		ctx = database.WithPrimary(ctx)
+	}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		ctx = database.WithLogAttrs(ctx, "team_id", actor.TeamID, "user_id", actor.UserID)
+	}
+
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return r.db.WithContext(ctx)
+}
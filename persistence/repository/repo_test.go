@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestWithinTx(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		errAbort := errors.New("abort")
+		score := 80
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			id, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":smile:"})
+			s.NoError(err)
+			s.NoError(repo.UpdateEmotion(ctx, id, domain.UpdateEmotionRequest{Score: &score}))
+
+			return errAbort
+		})
+		s.ErrorIs(err, errAbort)
+
+		emotions, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Empty(emotions)
+		var stats int64
+		s.NoError(db.Model(&repository.EmotionDailyStat{}).Count(&stats).Error)
+		s.Zero(stats)
+
+		// A failed nested unit of work only rolls back its own writes.
+		s.NoError(repo.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":smile:"})
+			s.NoError(err)
+
+			s.ErrorIs(repo.WithinTx(ctx, func(ctx context.Context) error {
+				_, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":cry:"})
+				s.NoError(err)
+
+				return errAbort
+			}), errAbort)
+
+			return nil
+		}))
+
+		emotions, err = repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U1"})
+		s.NoError(err)
+		s.Len(emotions, 1)
+		s.Equal(":smile:", emotions[0].Emoji)
+	})
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.RetentionRepository = (*GORMRepository)(nil)
+
+// maxUTCOffset is the largest offset of a time zone from UTC, no day ends later than that after it ends in UTC.
+const maxUTCOffset = 14 * time.Hour
+
+// pruneBatchSize is the number of emotions pruned by each statement, within the parameter limit of SQLite.
+const pruneBatchSize = 500
+
+// PruneEmotions rolls the emotions up into the daily statistics, then deletes or anonymizes them
+// in the same transaction.
+func (r *GORMRepository) PruneEmotions(ctx context.Context, req domain.PruneEmotionsRequest) (int64, error) {
+	before := req.Before.UTC().Format(dateLayout)
+
+	var pruned int64
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		scope := func(tx *gorm.DB) *gorm.DB {
+			tx = tx.Unscoped().Where("created_at < ?", req.Before.UTC().Add(maxUTCOffset))
+			if len(req.TeamIDs) > 0 {
+				tx = tx.Where("team_id IN ?", req.TeamIDs)
+			}
+			if len(req.ExcludeTeamIDs) > 0 {
+				tx = tx.Where("team_id NOT IN ?", req.ExcludeTeamIDs)
+			}
+			if req.Anonymize {
+				tx = tx.Where("user_id <> ''")
+			}
+			return tx
+		}
+
+		var candidates []Emotion
+		if err := tx.Scopes(scope).Select("id", "team_id", "channel_id", "user_id", "time_zone", "created_at").
+			Find(&candidates).Error; err != nil {
+			return fmt.Errorf("failed to list emotions to prune: %v", err)
+		}
+
+		// Only whole days of the time zone of each emotion are pruned, otherwise refreshing the rest of
+		// a day would drop the pruned emotions from its statistics.
+		var emotions []Emotion
+		var ids []int
+		for _, emotion := range candidates {
+			if bucketOf(emotion).Date < before {
+				emotions = append(emotions, emotion)
+				ids = append(ids, emotion.ID)
+			}
+		}
+
+		if err := refreshDailyStats(tx, bucketsOf(emotions...)); err != nil {
+			return err
+		}
+
+		for start := 0; start < len(ids); start += pruneBatchSize {
+			batch := ids[start:min(start+pruneBatchSize, len(ids))]
+
+			var result *gorm.DB
+			if req.Anonymize {
+				result = tx.Model(&Emotion{}).Unscoped().Where("id IN ?", batch).UpdateColumns(map[string]any{
+					"user_id":     "",
+					"description": "",
+					"task":        "",
+				})
+			} else {
+				result = tx.Unscoped().Where("id IN ?", batch).Delete(&Emotion{})
+			}
+			if result.Error != nil {
+				return fmt.Errorf("failed to prune emotions: %v", result.Error)
+			}
+
+			pruned += result.RowsAffected
+		}
+
+		return nil
+	})
+
+	return pruned, err
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestPruneEmotions(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		old := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+		create := func(teamID string, createdAt time.Time, score int) {
+			s.NoError(db.Create(&repository.Emotion{
+				CreatedAt: createdAt, TeamID: teamID, ChannelID: "C1", UserID: "U1",
+				Emoji: ":smile:", Description: "secret", Score: &score,
+			}).Error)
+		}
+		create("T1", old, 20)
+		create("T1", old.Add(time.Hour), 40)
+		create("T2", old, 60)
+		create("T1", time.Now(), 80)
+
+		before := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		pruned, err := repo.PruneEmotions(ctx, domain.PruneEmotionsRequest{Before: before, ExcludeTeamIDs: []string{"T2"}})
+		s.NoError(err)
+		s.EqualValues(2, pruned)
+
+		var stat repository.EmotionDailyStat
+		s.NoError(db.Where("team_id = ? AND date = ?", "T1", "2026-01-01").First(&stat).Error)
+		s.Equal(2, stat.Count)
+		s.InDelta(30, stat.AvgScore, 0.001)
+		s.Equal(20, stat.MinScore)
+		s.Equal(40, stat.MaxScore)
+
+		pruned, err = repo.PruneEmotions(ctx, domain.PruneEmotionsRequest{Before: before, TeamIDs: []string{"T2"}, Anonymize: true})
+		s.NoError(err)
+		s.EqualValues(1, pruned)
+
+		var anonymized repository.Emotion
+		s.NoError(db.Where("team_id = ?", "T2").First(&anonymized).Error)
+		s.Empty(anonymized.UserID)
+		s.Empty(anonymized.Description)
+		s.Equal(60, *anonymized.Score)
+
+		var count int64
+		s.NoError(db.Model(&repository.EmotionDailyStat{}).Count(&count).Error)
+		s.EqualValues(2, count)
+	})
+}
+
+func TestPruneEmotionsLocalDays(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		// Asia/Taipei is UTC+8, the UTC day of January 31 ends at 08:00 on February 1 there.
+		for _, createdAt := range []time.Time{
+			time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC),
+			time.Date(2026, 1, 31, 20, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 1, 2, 0, 0, 0, time.UTC),
+		} {
+			s.NoError(db.Create(&repository.Emotion{
+				CreatedAt: createdAt, TeamID: "T1", ChannelID: "C1", UserID: "U1", TimeZone: "Asia/Taipei",
+				Emoji: ":smile:", Version: 1,
+			}).Error)
+		}
+		_, err := repo.RebuildDailyStats(ctx)
+		s.NoError(err)
+
+		pruned, err := repo.PruneEmotions(ctx, domain.PruneEmotionsRequest{Before: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)})
+		s.NoError(err)
+		s.EqualValues(1, pruned)
+
+		// Recomputing the days keeps the pruned day and the whole day after it.
+		_, err = repo.RebuildDailyStats(ctx)
+		s.NoError(err)
+
+		counts := make(map[string]int)
+		var stats []repository.EmotionDailyStat
+		s.NoError(db.Find(&stats).Error)
+		for _, stat := range stats {
+			counts[stat.Date] = stat.Count
+		}
+		s.Equal(map[string]int{"2026-01-31": 1, "2026-02-01": 2}, counts)
+	})
+}
@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/migration"
+	"github.com/omegaatt36/cerberus/persistence/migration/cerberus"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+)
+
+func TestSchemaMatchesMigrations(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+
+		diff, err := migration.Verify(context.Background(), db, cerberus.MigrationList, func(db *gorm.DB) error {
+			return repository.NewGORMRepository(db).AutoMigrate()
+		})
+		s.NoError(err)
+		s.Empty(diff, "the migrations diverged from the models (- models only, + migrations only):\n%s", strings.Join(diff, "\n"))
+	})
+}
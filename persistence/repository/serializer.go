@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/omegaatt36/cerberus/persistence/encryption"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+type keyringContextKey struct{}
+
+// withKeyring returns the context of statements which encrypt with the keyring, nil stores as plain text.
+func withKeyring(ctx context.Context, k *encryption.Keyring) context.Context {
+	return context.WithValue(ctx, keyringContextKey{}, k)
+}
+
+// keyringFromContext returns the keyring of the statement context, nil if there is none.
+func keyringFromContext(ctx context.Context) *encryption.Keyring {
+	k, _ := ctx.Value(keyringContextKey{}).(*encryption.Keyring)
+	return k
+}
+
+// encryptedSerializer encrypts string fields on write and decrypts them on read, with the keyring of the
+// statement context. Plain text values written before the encryption was enabled are read as they are.
+// The values are bound to their table and column, the ID of a new row is not known before it is inserted.
+type encryptedSerializer struct{}
+
+// associatedData returns the data which the encrypted values of the field are bound to, e.g. "emotions.task".
+func associatedData(field *schema.Field) []byte {
+	return []byte(field.Schema.Table + "." + field.DBName)
+}
+
+// Scan implements schema.SerializerInterface.
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T of encrypted field %s", dbValue, field.Name)
+	}
+
+	if encryption.IsEncrypted(value) {
+		k := keyringFromContext(ctx)
+		if k == nil {
+			return fmt.Errorf("field %s is encrypted but no encryption key is configured", field.Name)
+		}
+
+		plaintext, err := k.Decrypt(value, associatedData(field))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt field %s: %w", field.Name, err)
+		}
+		value = string(plaintext)
+	}
+
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface.
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T of encrypted field %s", fieldValue, field.Name)
+	}
+
+	k := keyringFromContext(ctx)
+	if k == nil {
+		return value, nil
+	}
+
+	return k.Encrypt([]byte(value), associatedData(field))
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+)
+
+var _ domain.UserDataRepository = (*GORMRepository)(nil)
+
+// userOwnedTables lists every table which stores rows belonging to a user.
+// Tables storing user data must be added here so that PurgeUser removes them.
+var userOwnedTables = []struct {
+	model  any
+	column string
+	// teamKey matches the column as a "<team ID>:<user ID>" key instead of the user ID.
+	teamKey bool
+}{
+	{model: &Emotion{}, column: "user_id"},
+	{model: &Buddy{}, column: "user_id"},
+	{model: &Buddy{}, column: "buddy_user_id"},
+	{model: &AuditLog{}, column: "user_id"},
+	{model: &EmotionDailyStat{}, column: "user_id"},
+	{model: &AIUsage{}, column: "user_id"},
+	{model: &RateLimitBucket{}, column: "bucket_key", teamKey: true},
+}
+
+// PurgeUser hard deletes every row belonging to the user.
+func (r *GORMRepository) PurgeUser(ctx context.Context, userID string) (domain.PurgeUserResult, error) {
+	result := domain.PurgeUserResult{DeletedRows: make(map[string]int64)}
+	if userID == "" {
+		return result, fmt.Errorf("user ID is required")
+	}
+
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range userOwnedTables {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(table.model); err != nil {
+				return fmt.Errorf("failed to parse model: %v", err)
+			}
+
+			where := tx.Unscoped().Where(table.column+" = ?", userID)
+			if table.teamKey {
+				where = tx.Unscoped().Where(table.column+" LIKE ? ESCAPE '\\'", "%:"+escapeLike(userID))
+			}
+
+			deleted := where.Delete(table.model)
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to purge %s: %v", stmt.Schema.Table, deleted.Error)
+			}
+			result.DeletedRows[stmt.Schema.Table] += deleted.RowsAffected
+		}
+
+		details := make([]string, 0, len(result.DeletedRows))
+		for table, count := range result.DeletedRows {
+			details = append(details, fmt.Sprintf("%s=%d", table, count))
+		}
+		sort.Strings(details)
+
+		// The audit log deliberately contains no user ID.
+		if err := tx.Create(&AuditLog{
+			Action: string(domain.AuditActionUserPurged),
+			Detail: "deleted_rows " + strings.Join(details, " "),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to create audit log: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.PurgeUserResult{}, err
+	}
+
+	return result, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, with \ as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/omegaatt36/cerberus/domain"
+	"github.com/omegaatt36/cerberus/persistence/database"
+	"github.com/omegaatt36/cerberus/persistence/repository"
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+)
+
+func TestPurgeUser(t *testing.T) {
+	t.Parallel()
+
+	database.TestingEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		s := require.New(t)
+		ctx := context.Background()
+
+		repo := repository.NewGORMRepository(db)
+		s.NoError(repo.AutoMigrate())
+
+		deletedID, err := repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":cry:"})
+		s.NoError(err)
+		s.NoError(repo.DeleteEmotion(ctx, deletedID, "U1"))
+		_, err = repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U1", Emoji: ":smile:"})
+		s.NoError(err)
+		_, err = repo.CreateEmotion(ctx, domain.CreateEmotionRequest{UserID: "U2", Emoji: ":smile:"})
+		s.NoError(err)
+		_, err = repo.CreateBuddy(ctx, domain.CreateBuddyRequest{UserID: "U2", BuddyUserID: "U1", Threshold: 40, Days: 3})
+		s.NoError(err)
+
+		limit := ratelimit.Every(time.Minute, 5)
+		for _, key := range []string{"T1:U1", "T2:U1", "T1:U11", "T1:XU1"} {
+			_, _, err = repo.TakeToken(ctx, key, limit, time.Now())
+			s.NoError(err)
+		}
+
+		result, err := repo.PurgeUser(ctx, "U1")
+		s.NoError(err)
+		s.Equal(int64(2), result.DeletedRows["emotions"])
+		s.Equal(int64(1), result.DeletedRows["buddies"])
+		s.Equal(int64(2), result.DeletedRows["rate_limit_buckets"])
+
+		var keys []string
+		s.NoError(db.Model(&repository.RateLimitBucket{}).Order("bucket_key").Pluck("bucket_key", &keys).Error)
+		s.Equal([]string{"T1:U11", "T1:XU1"}, keys)
+
+		var remaining int64
+		s.NoError(db.Unscoped().Model(&repository.Emotion{}).Where("user_id = ?", "U1").Count(&remaining).Error)
+		s.Zero(remaining)
+
+		emotions, err := repo.ListEmotions(ctx, domain.ListEmotionsRequest{UserID: "U2"})
+		s.NoError(err)
+		s.Len(emotions, 1)
+
+		var auditLog repository.AuditLog
+		s.NoError(db.Where("action = ?", domain.AuditActionUserPurged).First(&auditLog).Error)
+		s.Empty(auditLog.UserID)
+		s.NotContains(auditLog.Detail, "U1")
+	})
+}
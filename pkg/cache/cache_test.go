@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	s.NoError(lru.SetCacheEntry(ctx, "a", "1", time.Minute))
+	s.NoError(lru.SetCacheEntry(ctx, "b", "2", time.Hour))
+
+	// Reading a makes b the least recently used entry.
+	value, ok, err := lru.GetCacheEntry(ctx, "a")
+	s.NoError(err)
+	s.True(ok)
+	s.Equal("1", value)
+
+	s.NoError(lru.SetCacheEntry(ctx, "c", "3", time.Hour))
+	_, ok, _ = lru.GetCacheEntry(ctx, "b")
+	s.False(ok)
+
+	now = now.Add(time.Minute)
+	_, ok, _ = lru.GetCacheEntry(ctx, "a")
+	s.False(ok)
+
+	value, ok, _ = lru.GetCacheEntry(ctx, "c")
+	s.True(ok)
+	s.Equal("3", value)
+}
@@ -2,6 +2,7 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -10,14 +11,48 @@ import (
 	"google.golang.org/api/option"
 )
 
+// Prompt versions identify the revision of each prompt in traces, bump them whenever the prompt changes.
+const (
+	PromptVersionEmotionScore   = "emotion-score/v1"
+	PromptVersionTaskSuggestion = "task-suggestion/v1"
+	PromptVersionDailySummary   = "daily-summary/v1"
+	PromptVersionDetectCrisis   = "detect-crisis/v1"
+)
+
+// Usage is the number of tokens used by a request.
+type Usage struct {
+	Model            string
+	PromptVersion    string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UsageHook is called with the token usage of every request, method is the name of the Service method.
+type UsageHook func(ctx context.Context, method string, usage Usage)
+
+// Option defines gemini service option.
+type Option interface {
+	apply(*Service)
+}
+
+// WithUsageHookOption defines the option to report the token usage.
+type WithUsageHookOption struct {
+	Hook UsageHook
+}
+
+func (o *WithUsageHookOption) apply(s *Service) {
+	s.usageHook = o.Hook
+}
+
 // Service is a wrapper around the Gemini client
 type Service struct {
-	model  string
-	client *genai.Client
+	model     string
+	client    *genai.Client
+	usageHook UsageHook
 }
 
 // NewService creates a new Gemini service
-func NewService(ctx context.Context, apiKey, model string) (*Service, error) {
+func NewService(ctx context.Context, apiKey, model string, options ...Option) (*Service, error) {
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %v", err)
@@ -49,10 +84,35 @@ func NewService(ctx context.Context, apiKey, model string) (*Service, error) {
 		return nil, fmt.Errorf("model %s not found", model)
 	}
 
-	return &Service{
+	service := &Service{
 		client: client,
 		model:  model,
-	}, nil
+	}
+
+	for _, option := range options {
+		option.apply(service)
+	}
+
+	return service, nil
+}
+
+// generateContent generates content from the prompt and reports the token usage of the method.
+func (g *Service) generateContent(ctx context.Context, method, promptVersion, prompt string) (*genai.GenerateContentResponse, error) {
+	resp, err := g.client.GenerativeModel(g.model).GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return nil, err
+	}
+
+	if g.usageHook != nil && resp.UsageMetadata != nil {
+		g.usageHook(ctx, method, Usage{
+			Model:            g.model,
+			PromptVersion:    promptVersion,
+			PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+			CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+		})
+	}
+
+	return resp, nil
 }
 
 // Close closes the Gemini client
@@ -64,7 +124,7 @@ func (g *Service) Close() error {
 func (g *Service) GetEmotionScore(ctx context.Context, input string) (int, error) {
 	const formatGetEmotionScorePrompt = `Analyze the emotion in the following text or emoji and provide a score from 0 to 100, where 0 is very negative and 100 is very positive. Only respond with the number, no other text. Text to analyze: %s`
 
-	resp, err := g.client.GenerativeModel(g.model).GenerateContent(ctx, genai.Text(fmt.Sprintf(formatGetEmotionScorePrompt, input)))
+	resp, err := g.generateContent(ctx, "GetEmotionScore", PromptVersionEmotionScore, fmt.Sprintf(formatGetEmotionScorePrompt, input))
 	if err != nil {
 		return 0, fmt.Errorf("error generating content: %v", err)
 	}
@@ -137,8 +197,8 @@ func (g *Service) GenerateTaskSuggestion(ctx context.Context, emoji string, desc
 	- 對於負面情緒，用幽默來緩解，但不要嘲笑用戶的感受。
 	- 對於正面情緒，用誇張的方式讚美，讓用戶笑得更開心。
 	- 可以適當使用一些無厘頭的幽默，但要確保不會冒犯到用戶。`
-	resp, err := g.client.GenerativeModel(g.model).GenerateContent(ctx,
-		genai.Text(fmt.Sprintf(formatGenerateTaskSuggestionPrompt, emoji, description, score)))
+	resp, err := g.generateContent(ctx, "GenerateTaskSuggestion", PromptVersionTaskSuggestion,
+		fmt.Sprintf(formatGenerateTaskSuggestionPrompt, emoji, description, score))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate task suggestion: %v", err)
 	}
@@ -161,7 +221,7 @@ func (g *Service) GenerateTaskSuggestion(ctx context.Context, emoji string, desc
 func (g *Service) GenerateDailySummary(ctx context.Context, average float64) (string, error) {
 	const formatGenerateDailySummaryPrompt = `Based on the average emotion score of %.2f (0-100, where 0 is very negative and 100 is very positive), provide a brief summary in Traditional Chinese about the overall mood and a general suggestion for improvement. Keep it concise and positive.`
 
-	resp, err := g.client.GenerativeModel(g.model).GenerateContent(ctx, genai.Text(fmt.Sprintf(formatGenerateDailySummaryPrompt, average)))
+	resp, err := g.generateContent(ctx, "GenerateDailySummary", PromptVersionDailySummary, fmt.Sprintf(formatGenerateDailySummaryPrompt, average))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate summary: %v", err)
 	}
@@ -179,3 +239,31 @@ func (g *Service) GenerateDailySummary(ctx context.Context, average float64) (st
 
 	return strings.TrimSpace(summary), nil
 }
+
+// DetectCrisis asks Gemini whether the input indicates a risk of self-harm or an acute crisis
+func (g *Service) DetectCrisis(ctx context.Context, input string) (bool, error) {
+	const formatDetectCrisisPrompt = `You are a safety classifier for a workplace mood check-in bot. Decide whether the following text or emoji indicates a risk of self-harm, suicide, or an acute mental health crisis. Only respond with YES or NO, no other text. Text to classify: %s`
+
+	resp, err := g.generateContent(ctx, "DetectCrisis", PromptVersionDetectCrisis, fmt.Sprintf(formatDetectCrisisPrompt, input))
+	if err != nil {
+		// A blocked prompt or response means Gemini itself considered the content harmful.
+		var blockedErr *genai.BlockedError
+		if errors.As(err, &blockedErr) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to detect crisis: %v", err)
+	}
+
+	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+		return false, fmt.Errorf("no response received for crisis detection")
+	}
+
+	var answer string
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if textPart, ok := part.(genai.Text); ok {
+			answer += string(textPart)
+		}
+	}
+
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "YES"), nil
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore stores the buckets in memory, it is only accurate for a single replica.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]Bucket
+}
+
+// NewMemoryStore creates a new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]Bucket)}
+}
+
+// TakeToken implements Store.
+func (s *MemoryStore) TakeToken(_ context.Context, key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = NewBucket(limit, now)
+	}
+
+	bucket, allowed, retryAfter := bucket.Take(limit, now)
+	s.buckets[key] = bucket
+
+	return allowed, retryAfter, nil
+}
+
+// Cleanup removes the buckets which are full again at now, as they are the same as new buckets.
+func (s *MemoryStore) Cleanup(limit Limit, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, bucket := range s.buckets {
+		if bucket.Refill(limit, now).Tokens >= float64(limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RunCleanup cleans up the buckets periodically until the context is cancelled.
+func (s *MemoryStore) RunCleanup(ctx context.Context, limit Limit, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Cleanup(limit, now)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package ratelimit implements token bucket rate limiting with pluggable bucket stores.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit defines a token bucket which holds up to Burst tokens and refills Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Every returns the limit which allows burst events at once and refills one token every interval.
+func Every(interval time.Duration, burst int) Limit {
+	return Limit{Rate: 1 / interval.Seconds(), Burst: burst}
+}
+
+// Bucket is the state of a token bucket.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// NewBucket returns a full bucket.
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Refill returns the bucket refilled up to now.
+func (b Bucket) Refill(limit Limit, now time.Time) Bucket {
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed.Seconds()*limit.Rate)
+		b.UpdatedAt = now
+	}
+
+	return b
+}
+
+// Take refills the bucket up to now and takes a token. It returns the new state of the bucket, whether
+// a token was taken, and otherwise how long until the next token is available.
+func (b Bucket) Take(limit Limit, now time.Time) (Bucket, bool, time.Duration) {
+	b = b.Refill(limit, now)
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return b, true, 0
+	}
+
+	if limit.Rate <= 0 {
+		return b, false, math.MaxInt64
+	}
+
+	return b, false, time.Duration((1 - b.Tokens) / limit.Rate * float64(time.Second))
+}
+
+// Store stores the buckets by key.
+type Store interface {
+	// TakeToken takes a token from the bucket of the key atomically, see Bucket.Take.
+	TakeToken(ctx context.Context, key string, limit Limit, now time.Time) (bool, time.Duration, error)
+}
+
+// Limiter limits events by key.
+type Limiter struct {
+	store Store
+	limit Limit
+	now   func() time.Time
+}
+
+// NewLimiter creates a new Limiter.
+func NewLimiter(store Store, limit Limit) *Limiter {
+	return &Limiter{store: store, limit: limit, now: time.Now}
+}
+
+// Allow reports whether an event of the key may happen now, and otherwise how long until it may.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	return l.store.TakeToken(ctx, key, l.limit, l.now())
+}
@@ -0,0 +1,45 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/cerberus/pkg/ratelimit"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := require.New(t)
+	ctx := context.Background()
+
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Every(10*time.Second, 2)
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	for range 2 {
+		allowed, _, err := store.TakeToken(ctx, "T1:U1", limit, now)
+		s.NoError(err)
+		s.True(allowed)
+	}
+
+	allowed, retryAfter, err := store.TakeToken(ctx, "T1:U1", limit, now)
+	s.NoError(err)
+	s.False(allowed)
+	s.Equal(10*time.Second, retryAfter)
+
+	// Other keys have their own bucket.
+	allowed, _, err = store.TakeToken(ctx, "T1:U2", limit, now)
+	s.NoError(err)
+	s.True(allowed)
+
+	allowed, _, err = store.TakeToken(ctx, "T1:U1", limit, now.Add(10*time.Second))
+	s.NoError(err)
+	s.True(allowed)
+
+	store.Cleanup(limit, now.Add(time.Minute))
+	allowed, _, err = store.TakeToken(ctx, "T1:U1", limit, now.Add(time.Minute))
+	s.NoError(err)
+	s.True(allowed)
+}
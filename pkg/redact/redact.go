@@ -0,0 +1,85 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Placeholders replacing the redacted content.
+const (
+	PlaceholderUser    = "[USER]"
+	PlaceholderChannel = "[CHANNEL]"
+	PlaceholderGroup   = "[GROUP]"
+	PlaceholderEmail   = "[EMAIL]"
+	PlaceholderURL     = "[URL]"
+	PlaceholderPhone   = "[PHONE]"
+	PlaceholderTerm    = "[REDACTED]"
+)
+
+// minPhoneDigits is the minimum number of digits of a phone number, which keeps dates and scores intact.
+const minPhoneDigits = 9
+
+var (
+	slackUserRegexp    = regexp.MustCompile(`<@[UW][A-Z0-9]+(\|[^>]*)?>`)
+	slackChannelRegexp = regexp.MustCompile(`<#C[A-Z0-9]+(\|[^>]*)?>`)
+	slackGroupRegexp   = regexp.MustCompile(`<!(subteam\^[A-Z0-9]+|here|channel|everyone)(\|[^>]*)?>`)
+	slackMailtoRegexp  = regexp.MustCompile(`<mailto:[^>]*>`)
+	slackLinkRegexp    = regexp.MustCompile(`<https?://[^>]*>`)
+	emailRegexp        = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	urlRegexp          = regexp.MustCompile(`(https?://|www\.)[^\s<>]+`)
+	phoneRegexp        = regexp.MustCompile(`\+?\(?\d[\d\s\-().]{6,}\d`)
+)
+
+// Redactor replaces personal information in text with placeholders.
+type Redactor struct {
+	terms *regexp.Regexp
+}
+
+// NewRedactor creates a Redactor which also redacts the custom terms, case-insensitively.
+func NewRedactor(terms []string) *Redactor {
+	var quoted []string
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+
+	r := &Redactor{}
+	if len(quoted) > 0 {
+		r.terms = regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	}
+
+	return r
+}
+
+// Redact replaces Slack mentions, emails, URLs, phone numbers and the custom terms in the text.
+func (r *Redactor) Redact(text string) string {
+	// Slack escapes mentions and links as <...>, they are replaced as a whole first.
+	text = slackUserRegexp.ReplaceAllString(text, PlaceholderUser)
+	text = slackChannelRegexp.ReplaceAllString(text, PlaceholderChannel)
+	text = slackGroupRegexp.ReplaceAllString(text, PlaceholderGroup)
+	text = slackMailtoRegexp.ReplaceAllString(text, PlaceholderEmail)
+	text = slackLinkRegexp.ReplaceAllString(text, PlaceholderURL)
+
+	text = emailRegexp.ReplaceAllString(text, PlaceholderEmail)
+	text = urlRegexp.ReplaceAllString(text, PlaceholderURL)
+	text = phoneRegexp.ReplaceAllStringFunc(text, func(match string) string {
+		var digits int
+		for _, c := range match {
+			if unicode.IsDigit(c) {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits {
+			return match
+		}
+		return PlaceholderPhone
+	})
+
+	if r.terms != nil {
+		text = r.terms.ReplaceAllString(text, PlaceholderTerm)
+	}
+
+	return text
+}
@@ -0,0 +1,51 @@
+package redact_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/omegaatt36/cerberus/pkg/redact"
+)
+
+func TestRedact(t *testing.T) {
+	r := redact.NewRedactor([]string{"Project Phoenix", "王小明"})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "slack mentions",
+			input: "<@U0123ABC|alice> broke <#C0123ABC|general> again, cc <!subteam^S0123ABC> <!here>",
+			want:  "[USER] broke [CHANNEL] again, cc [GROUP] [GROUP]",
+		},
+		{
+			name:  "emails and links",
+			input: "mail <mailto:bob@example.com|bob@example.com> or carol@example.org, see <https://example.com/x|doc> and https://example.com/y",
+			want:  "mail [EMAIL] or [EMAIL], see [URL] and [URL]",
+		},
+		{
+			name:  "phone numbers",
+			input: "call 0912-345-678 or +1 (415) 555-0100",
+			want:  "call [PHONE] or [PHONE]",
+		},
+		{
+			name:  "dates and scores are kept",
+			input: "2026-10-19 was a 7/10 day, 100%",
+			want:  "2026-10-19 was a 7/10 day, 100%",
+		},
+		{
+			name:  "custom terms",
+			input: "project phoenix is late and 王小明 is sad",
+			want:  "[REDACTED] is late and [REDACTED] is sad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Redact(tt.input))
+		})
+	}
+}